@@ -17,6 +17,11 @@ import (
 func Cmd() command.SetupCommand[*config.Config] {
 	ListenAddress := config.DefaultListenAddress
 	Endpoint := config.DefaultEndpoint
+	RegistryFile := ""
+	TLSCertFile := ""
+	TLSKeyFile := ""
+	TLSClientCAFile := ""
+	TLSSelfSigned := false
 
 	return func(cmd *cobra.Command, ch *cmdutils.Helper[*config.Config]) {
 		apiCmd := &cobra.Command{
@@ -31,7 +36,31 @@ func Cmd() command.SetupCommand[*config.Config] {
 				ch.Config.ListenAddress = ListenAddress
 				ch.Config.Endpoint = Endpoint
 
-				return ch.Config.Validate()
+				// The registry file can also be configured in the config file, which the flag only overrides if set
+				if cmd.Flags().Changed("registry-file") {
+					ch.Config.RegistryFile = RegistryFile
+				}
+
+				err = ch.Config.Validate()
+				if err != nil {
+					return err
+				}
+
+				// TLS can also be configured in the config file, which the flags only override if set
+				if cmd.Flags().Changed("tls-cert") {
+					ch.Config.TLSCertFile = TLSCertFile
+				}
+				if cmd.Flags().Changed("tls-key") {
+					ch.Config.TLSKeyFile = TLSKeyFile
+				}
+				if cmd.Flags().Changed("tls-client-ca") {
+					ch.Config.TLSClientCAFile = TLSClientCAFile
+				}
+				if cmd.Flags().Changed("tls-self-signed") {
+					ch.Config.TLSSelfSigned = TLSSelfSigned
+				}
+
+				return ch.Config.ValidateTLS()
 			},
 			RunE: func(cmd *cobra.Command, args []string) error {
 				ch.Printer.Println("starting Flux API listening on ", ch.Config.ListenAddress)
@@ -58,5 +87,10 @@ func Cmd() command.SetupCommand[*config.Config] {
 
 		apiCmd.Flags().StringVar(&ListenAddress, "listen-address", config.DefaultListenAddress, "The address to listen on")
 		apiCmd.Flags().StringVar(&Endpoint, "endpoint", config.DefaultEndpoint, "The endpoint to listen on")
+		apiCmd.Flags().StringVar(&RegistryFile, "registry-file", "", "The file registered machines are persisted to (defaults to a file in the config directory)")
+		apiCmd.Flags().StringVar(&TLSCertFile, "tls-cert", "", "The certificate to serve the API over TLS with")
+		apiCmd.Flags().StringVar(&TLSKeyFile, "tls-key", "", "The key of the TLS certificate")
+		apiCmd.Flags().StringVar(&TLSClientCAFile, "tls-client-ca", "", "Require clients to present a certificate signed by this CA")
+		apiCmd.Flags().BoolVar(&TLSSelfSigned, "tls-self-signed", false, "Serve the API over TLS with a self-signed certificate, generated on first run")
 	}
 }
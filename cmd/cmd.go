@@ -4,6 +4,7 @@ import (
 	"github.com/loopholelabs/cmdutils/pkg/command"
 
 	"github.com/shivanshvij/flux/cmd/api"
+	"github.com/shivanshvij/flux/cmd/token"
 	"github.com/shivanshvij/flux/internal/config"
 	"github.com/shivanshvij/flux/version"
 )
@@ -15,5 +16,5 @@ var Cmd = command.New[*config.Config](
 	true,
 	version.V,
 	config.New,
-	[]command.SetupCommand[*config.Config]{api.Cmd()},
+	[]command.SetupCommand[*config.Config]{api.Cmd(), token.Cmd()},
 )
@@ -0,0 +1,142 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/loopholelabs/cmdutils"
+	"github.com/loopholelabs/cmdutils/pkg/command"
+	"github.com/loopholelabs/cmdutils/pkg/printer"
+
+	"github.com/shivanshvij/flux/internal/config"
+	"github.com/shivanshvij/flux/pkg/auth"
+)
+
+type tokenModel struct {
+	ID        string `header:"id" json:"id"`
+	Name      string `header:"name" json:"name"`
+	Scope     string `header:"scope" json:"scope"`
+	CreatedAt string `header:"created_at" json:"created_at"`
+}
+
+type createdTokenModel struct {
+	ID    string `header:"id" json:"id"`
+	Name  string `header:"name" json:"name"`
+	Scope string `header:"scope" json:"scope"`
+	Token string `header:"token" json:"token"`
+}
+
+// Cmd encapsulates the commands for managing API tokens
+func Cmd() command.SetupCommand[*config.Config] {
+	TokensFile := ""
+
+	return func(cmd *cobra.Command, ch *cmdutils.Helper[*config.Config]) {
+		tokenCmd := &cobra.Command{
+			Use:   "token",
+			Short: "Manage the tokens used to authenticate with the Flux API",
+			PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+				err := ch.Config.GlobalRequiredFlags(cmd)
+				if err != nil {
+					return err
+				}
+
+				err = ch.Config.Validate()
+				if err != nil {
+					return err
+				}
+				if TokensFile != "" {
+					ch.Config.TokensFile = TokensFile
+				}
+				return nil
+			},
+		}
+
+		store := func() (*auth.Store, error) {
+			path, err := ch.Config.TokensPath()
+			if err != nil {
+				return nil, err
+			}
+			return auth.NewStore(path)
+		}
+
+		var scope string
+		createCmd := &cobra.Command{
+			Use:   "create <name>",
+			Short: "Create a token, which is only shown once",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				s, err := store()
+				if err != nil {
+					return err
+				}
+				t, secret, err := s.Create(args[0], auth.Scope(scope))
+				if err != nil {
+					return fmt.Errorf("failed to create token: %w", err)
+				}
+				if ch.Printer.Format() == printer.Human {
+					ch.Printer.Printf("Created %s token %s, it will not be shown again:\n\n%s\n", t.Scope, printer.BoldGreen(t.Name), secret)
+					return nil
+				}
+				return ch.Printer.PrintResource(createdTokenModel{
+					ID:    t.ID,
+					Name:  t.Name,
+					Scope: string(t.Scope),
+					Token: secret,
+				})
+			},
+		}
+		createCmd.Flags().StringVar(&scope, "scope", string(auth.ScopeRead), fmt.Sprintf("The scope of the token, one of %v", auth.Scopes))
+
+		listCmd := &cobra.Command{
+			Use:   "list",
+			Short: "List every token",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				s, err := store()
+				if err != nil {
+					return err
+				}
+				tokens := s.Tokens()
+				if len(tokens) == 0 && ch.Printer.Format() == printer.Human {
+					ch.Printer.Println("No tokens exist, so requests to the Flux API are not authenticated")
+					return nil
+				}
+				models := make([]tokenModel, len(tokens))
+				for i, t := range tokens {
+					models[i] = tokenModel{
+						ID:        t.ID,
+						Name:      t.Name,
+						Scope:     string(t.Scope),
+						CreatedAt: t.CreatedAt.Format(time.RFC3339),
+					}
+				}
+				return ch.Printer.PrintResource(models)
+			},
+		}
+
+		revokeCmd := &cobra.Command{
+			Use:   "revoke <id or name>",
+			Short: "Revoke a token, which takes effect immediately",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				s, err := store()
+				if err != nil {
+					return err
+				}
+				t, err := s.Revoke(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to revoke token: %w", err)
+				}
+				ch.Printer.Printf("Revoked token %s (%s)\n", printer.BoldGreen(t.Name), t.ID)
+				return nil
+			},
+		}
+
+		tokenCmd.AddCommand(createCmd, listCmd, revokeCmd)
+		cmd.AddCommand(tokenCmd)
+
+		tokenCmd.PersistentFlags().StringVar(&TokensFile, "tokens-file", "", "The file API tokens are persisted to (defaults to a file in the config directory)")
+	}
+}
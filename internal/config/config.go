@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"path"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/spf13/cobra"
@@ -22,6 +24,14 @@ const (
 	defaultConfigFile = "flux.yml"
 	defaultLogFile    = "flux.log"
 
+	defaultRegistryFile    = "flux-machines.json"
+	defaultMaintenanceFile = "flux-maintenance.json"
+	defaultQueueDirectory  = "flux-queue"
+	defaultWebhooksFile    = "flux-webhooks.json"
+	defaultTokensFile      = "flux-tokens.json"
+	defaultTLSCertFile     = "flux-tls.crt"
+	defaultTLSKeyFile      = "flux-tls.key"
+
 	DefaultListenAddress = "127.0.0.1:8080"
 	DefaultEndpoint      = "localhost:8080"
 )
@@ -30,6 +40,48 @@ const (
 type Config struct {
 	ListenAddress string `mapstructure:"listen_address"`
 	Endpoint      string `mapstructure:"endpoint"`
+	RegistryFile  string `mapstructure:"registry_file"`
+	TokensFile    string `mapstructure:"tokens_file"`
+	CORSOrigins   string `mapstructure:"cors_origins"` // Comma separated origins allowed to make cross-origin requests, any origin if empty
+
+	TLSCertFile     string `mapstructure:"tls_cert_file"`
+	TLSKeyFile      string `mapstructure:"tls_key_file"`
+	TLSClientCAFile string `mapstructure:"tls_client_ca_file"` // Clients must present a certificate signed by this CA if set
+	TLSSelfSigned   bool   `mapstructure:"tls_self_signed"`    // Generate a self-signed certificate if no certificate is configured
+
+	RequestTimeout  time.Duration `mapstructure:"request_timeout"`  // Overrides the time every request to a machine waits for a response
+	RequestAttempts int           `mapstructure:"request_attempts"` // Overrides the attempts of requests that are retried, which only retrieve information
+
+	OutboundQueueSize int           `mapstructure:"outbound_queue_size"` // Requests queued for each machine before new requests are rejected
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	PingInterval      time.Duration `mapstructure:"ping_interval"`
+	StaleTimeout      time.Duration `mapstructure:"stale_timeout"` // Connections that receive nothing for this long are redialed
+
+	MaintenanceFile     string        `mapstructure:"maintenance_file"`
+	MaintenanceWarning  float64       `mapstructure:"maintenance_warning"`
+	MaintenanceCritical float64       `mapstructure:"maintenance_critical"`
+	MaintenanceWindow   time.Duration `mapstructure:"maintenance_window"`
+	ScreenLifetime      time.Duration `mapstructure:"screen_lifetime"`
+
+	QueueDirectory string `mapstructure:"queue_directory"`
+
+	WebhooksFile       string        `mapstructure:"webhooks_file"`
+	WebhookMaxAttempts int           `mapstructure:"webhook_max_attempts"`
+	WebhookBackoff     time.Duration `mapstructure:"webhook_backoff"`
+	WebhookTimeout     time.Duration `mapstructure:"webhook_timeout"`
+
+	MQTTBroker          string `mapstructure:"mqtt_broker"` // The MQTT bridge is only started if a broker is configured
+	MQTTClientID        string `mapstructure:"mqtt_client_id"`
+	MQTTUsername        string `mapstructure:"mqtt_username"`
+	MQTTPassword        string `mapstructure:"mqtt_password"`
+	MQTTPrefix          string `mapstructure:"mqtt_prefix"`
+	MQTTDiscovery       bool   `mapstructure:"mqtt_discovery"`
+	MQTTDiscoveryPrefix string `mapstructure:"mqtt_discovery_prefix"`
+
+	DiscoveryInterval     time.Duration `mapstructure:"discovery_interval"` // Background discovery only runs if an interval is configured
+	DiscoveryAutoRegister bool          `mapstructure:"discovery_auto_register"`
+	DiscoveryAllow        []string      `mapstructure:"discovery_allow"` // Mainboard IDs, models or subnets of printers that are registered or updated
+	DiscoveryDeny         []string      `mapstructure:"discovery_deny"`  // Mainboard IDs, models or subnets of printers that are never registered or updated
 }
 
 func New() *Config {
@@ -57,6 +109,100 @@ func (c *Config) DefaultConfigDir() (string, error) {
 	return xdg.ConfigHome, nil
 }
 
+// RegistryPath returns the path of the file that registered machines are persisted to,
+// which defaults to a file in the default configuration directory
+func (c *Config) RegistryPath() (string, error) {
+	if c.RegistryFile != "" {
+		return c.RegistryFile, nil
+	}
+	dir, err := c.DefaultConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, defaultRegistryFile), nil
+}
+
+// MaintenancePath returns the path of the file that consumable wear is persisted to,
+// which defaults to a file in the default configuration directory
+func (c *Config) MaintenancePath() (string, error) {
+	if c.MaintenanceFile != "" {
+		return c.MaintenanceFile, nil
+	}
+	dir, err := c.DefaultConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, defaultMaintenanceFile), nil
+}
+
+// QueuePath returns the directory that queued jobs and their files are persisted to,
+// which defaults to a directory in the default data directory
+func (c *Config) QueuePath() (string, error) {
+	if c.QueueDirectory != "" {
+		return c.QueueDirectory, nil
+	}
+	return path.Join(c.DefaultDataDir(), defaultQueueDirectory), nil
+}
+
+// TokensPath returns the path of the file that hashed API tokens are persisted to,
+// which defaults to a file in the default configuration directory
+func (c *Config) TokensPath() (string, error) {
+	if c.TokensFile != "" {
+		return c.TokensFile, nil
+	}
+	dir, err := c.DefaultConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, defaultTokensFile), nil
+}
+
+// ValidateTLS returns an error if the TLS configuration is incomplete
+func (c *Config) ValidateTLS() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	}
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		return fmt.Errorf("tls_client_ca_file requires a tls certificate or tls_self_signed")
+	}
+	return nil
+}
+
+// TLSEnabled returns true if the API is served over TLS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSSelfSigned || (c.TLSCertFile != "" && c.TLSKeyFile != "")
+}
+
+// TLSPaths returns the paths of the certificate and key the API is served with. Self-signed
+// certificates default to files in the default configuration directory.
+func (c *Config) TLSPaths() (string, string, error) {
+	if c.TLSCertFile != "" && c.TLSKeyFile != "" {
+		return c.TLSCertFile, c.TLSKeyFile, nil
+	}
+	dir, err := c.DefaultConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+	return path.Join(dir, defaultTLSCertFile), path.Join(dir, defaultTLSKeyFile), nil
+}
+
+// WebhooksPath returns the path of the file that webhooks are persisted to,
+// which defaults to a file in the default configuration directory
+func (c *Config) WebhooksPath() (string, error) {
+	if c.WebhooksFile != "" {
+		return c.WebhooksFile, nil
+	}
+	dir, err := c.DefaultConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, defaultWebhooksFile), nil
+}
+
+func (c *Config) DefaultDataDir() string {
+	return xdg.DataHome
+}
+
 func (c *Config) DefaultConfigFile() string {
 	return defaultConfigFile
 }
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	selfSignedValidity = 365 * 24 * time.Hour
+	// selfSignedRenewal is how long before it expires a self-signed certificate is replaced
+	selfSignedRenewal = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidCertificate = errors.New("invalid tls certificate")
+	ErrInvalidClientCA    = errors.New("invalid tls client ca")
+)
+
+// TLSConfig loads the certificate and key at the given paths. If clientCAPath is not empty, clients
+// must present a certificate signed by one of the certificates in it.
+func TLSConfig(certPath string, keyPath string, clientCAPath string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidCertificate, err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if clientCAPath != "" {
+		data, err := os.ReadFile(clientCAPath)
+		if err != nil {
+			return nil, errors.Join(ErrInvalidClientCA, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w: no certificates found in %s", ErrInvalidClientCA, clientCAPath)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// EnsureSelfSignedCertificate generates a self-signed certificate and key for the given hosts at the
+// given paths, unless a certificate that is valid for every host and does not expire soon already exists
+func EnsureSelfSignedCertificate(certPath string, keyPath string, hosts []string) (bool, error) {
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err == nil && time.Now().Add(selfSignedRenewal).Before(leaf.NotAfter) && !slices.ContainsFunc(hosts, func(host string) bool {
+			return leaf.VerifyHostname(host) != nil
+		}) {
+			return false, nil
+		}
+	}
+	return true, GenerateSelfSignedCertificate(certPath, keyPath, hosts)
+}
+
+// GenerateSelfSignedCertificate writes a new self-signed certificate and key for the given hosts,
+// which may be host names or IP addresses, to the given paths
+func GenerateSelfSignedCertificate(certPath string, keyPath string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Flux"}, CommonName: "Flux self-signed certificate"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !slices.ContainsFunc(template.IPAddresses, ip.Equal) {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "" && !slices.Contains(template.DNSNames, host) {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = WriteFileAtomic(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
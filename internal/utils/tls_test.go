@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "flux.crt")
+	keyPath := filepath.Join(dir, "flux.key")
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+
+	generated, err := EnsureSelfSignedCertificate(certPath, keyPath, hosts)
+	require.NoError(t, err)
+	require.True(t, generated)
+
+	// The certificate is reused as long as it is valid for every host
+	generated, err = EnsureSelfSignedCertificate(certPath, keyPath, hosts)
+	require.NoError(t, err)
+	require.False(t, generated)
+	generated, err = EnsureSelfSignedCertificate(certPath, keyPath, append(hosts, "flux.local"))
+	require.NoError(t, err)
+	require.True(t, generated)
+
+	_, err = TLSConfig(certPath, keyPath, filepath.Join(dir, "missing.crt"))
+	require.ErrorIs(t, err, ErrInvalidClientCA)
+	_, err = TLSConfig(certPath, certPath, "")
+	require.ErrorIs(t, err, ErrInvalidCertificate)
+
+	clientPath := filepath.Join(dir, "client.crt")
+	client := clientCertificate(t, clientPath)
+	config, err := TLSConfig(certPath, keyPath, clientPath)
+	require.NoError(t, err)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_, _ = conn.Write([]byte{1})
+			_ = conn.Close()
+		}
+	}()
+
+	data, err := os.ReadFile(certPath)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(data))
+	dial := func(certificates []tls.Certificate) error {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			RootCAs:      pool,
+			ServerName:   "localhost",
+			Certificates: certificates,
+		})
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = conn.Read(make([]byte, 1))
+		return err
+	}
+
+	require.Error(t, dial(nil))
+	require.NoError(t, dial([]tls.Certificate{client}))
+}
+
+// clientCertificate writes a self-signed client certificate to path, to be used as its own CA
+func clientCertificate(t *testing.T, path string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -1,8 +1,21 @@
 package api
 
 import (
+	"crypto/tls"
+	"github.com/shivanshvij/flux/pkg/auth"
+	"github.com/shivanshvij/flux/pkg/discovery"
+	"github.com/shivanshvij/flux/pkg/maintenance"
+	"github.com/shivanshvij/flux/pkg/metrics"
+	"github.com/shivanshvij/flux/pkg/mqtt"
+	"github.com/shivanshvij/flux/pkg/queue"
+	"github.com/shivanshvij/flux/pkg/registry"
 	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/webhook"
 	"net"
+	"os"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -25,7 +38,13 @@ type API struct {
 	config *config.Config
 	app    *fiber.App
 
-	sdcp *sdcp.SDCP
+	sdcp        *sdcp.SDCP
+	maintenance *maintenance.Tracker
+	queue       *queue.Queue
+	webhooks    *webhook.Manager
+	mqtt        *mqtt.Bridge
+	discovery   *discovery.Service
+	tokens      *auth.Store
 }
 
 func New(config *config.Config, logger types.Logger) *API {
@@ -42,17 +61,215 @@ func (s *API) Start() error {
 		return err
 	}
 
-	s.sdcp = sdcp.New(s.logger)
+	registryPath, err := s.config.RegistryPath()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	s.sdcp = sdcp.New(s.logger, registry.NewFile(registryPath))
+	for command, policy := range sdcp.DefaultRequestPolicies {
+		if s.config.RequestTimeout > 0 {
+			policy.Timeout = s.config.RequestTimeout
+		}
+		if s.config.RequestAttempts > 0 && policy.Attempts > 1 {
+			policy.Attempts = s.config.RequestAttempts
+		}
+		s.sdcp.SetRequestPolicy(command, policy)
+	}
+	s.sdcp.SetConnectionOptions(sdcp.ConnectionOptions{
+		QueueSize:    s.config.OutboundQueueSize,
+		WriteTimeout: s.config.WriteTimeout,
+		PingInterval: s.config.PingInterval,
+		StaleTimeout: s.config.StaleTimeout,
+	})
+	err = s.sdcp.Restore()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	maintenancePath, err := s.config.MaintenancePath()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.maintenance, err = maintenance.New(s.sdcp, maintenancePath, maintenance.Options{
+		Warning:        s.config.MaintenanceWarning,
+		Critical:       s.config.MaintenanceCritical,
+		ScreenLifetime: s.config.ScreenLifetime,
+		Window:         s.config.MaintenanceWindow,
+	}, s.logger)
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.maintenance.Start()
+
+	queuePath, err := s.config.QueuePath()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.queue, err = queue.New(s.sdcp, queuePath, s.logger)
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.queue.Start()
+
+	webhooksPath, err := s.config.WebhooksPath()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.webhooks, err = webhook.New(s.sdcp, webhooksPath, webhook.Options{
+		MaxAttempts: s.config.WebhookMaxAttempts,
+		Backoff:     s.config.WebhookBackoff,
+		Timeout:     s.config.WebhookTimeout,
+	}, s.logger)
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.webhooks.Start()
+
+	if s.config.MQTTBroker != "" {
+		s.mqtt = mqtt.New(s.sdcp, mqtt.Options{
+			Broker:          s.config.MQTTBroker,
+			ClientID:        s.config.MQTTClientID,
+			Username:        s.config.MQTTUsername,
+			Password:        s.config.MQTTPassword,
+			Prefix:          s.config.MQTTPrefix,
+			Discovery:       s.config.MQTTDiscovery,
+			DiscoveryPrefix: s.config.MQTTDiscoveryPrefix,
+		}, s.logger)
+		err = s.mqtt.Start()
+		if err != nil {
+			_ = listener.Close()
+			return err
+		}
+	}
+
+	if s.config.DiscoveryInterval > 0 {
+		s.discovery = discovery.New(s.sdcp, discovery.Options{
+			Interval:     s.config.DiscoveryInterval,
+			AutoRegister: s.config.DiscoveryAutoRegister,
+			Allow:        discovery.ParsePolicy(s.config.DiscoveryAllow),
+			Deny:         discovery.ParsePolicy(s.config.DiscoveryDeny),
+		}, s.logger)
+		s.discovery.Start()
+	}
+
+	tokensPath, err := s.config.TokensPath()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.tokens, err = auth.NewStore(tokensPath)
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	if !s.tokens.Enabled() {
+		s.logger.Warn().Msg("no api tokens exist, so requests are not authenticated, create one with flux token create")
+	}
+
 	v1Docs.SwaggerInfoapi.Host = s.config.Endpoint
 	v1Docs.SwaggerInfoapi.Schemes = []string{"http"}
+	if s.config.TLSEnabled() {
+		tlsConfig, err := s.tlsConfig()
+		if err != nil {
+			_ = listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+		v1Docs.SwaggerInfoapi.Schemes = []string{"https"}
+	}
 
-	s.app.Use(cors.New())
-	s.app.Mount(V1Path, v1.New(s.sdcp, s.logger).App())
+	m := metrics.New(s.sdcp)
+	s.app.Use(cors.New(cors.Config{
+		AllowOrigins: s.corsOrigins(),
+		AllowHeaders: strings.Join([]string{fiber.HeaderOrigin, fiber.HeaderContentType, fiber.HeaderAccept, fiber.HeaderAuthorization, auth.HeaderAPIKey}, ","),
+	}))
+	s.app.Use(m.Middleware())
+	s.app.Use(auth.Middleware(s.tokens, scope, s.logger))
+	s.app.Get("/metrics", m.Handler())
+	s.app.Mount(V1Path, v1.New(s.sdcp, s.maintenance, s.queue, s.webhooks, s.logger).App())
 
 	return s.app.Listener(listener)
 }
 
 func (s *API) Stop() error {
+	if s.discovery != nil {
+		s.discovery.Stop()
+	}
+	if s.mqtt != nil {
+		s.mqtt.Stop()
+	}
+	s.webhooks.Stop()
+	s.queue.Stop()
+	s.maintenance.Stop()
 	s.sdcp.Close()
 	return s.app.Shutdown()
 }
+
+// tlsConfig loads the configured certificate, generating a self-signed certificate
+// for the local host and the configured endpoint first if required
+func (s *API) tlsConfig() (*tls.Config, error) {
+	certPath, keyPath, err := s.config.TLSPaths()
+	if err != nil {
+		return nil, err
+	}
+	if s.config.TLSSelfSigned && (s.config.TLSCertFile == "" || s.config.TLSKeyFile == "") {
+		hosts := []string{"localhost", "127.0.0.1", "::1"}
+		if hostname, err := os.Hostname(); err == nil {
+			hosts = append(hosts, hostname)
+		}
+		for _, address := range []string{s.config.Endpoint, s.config.ListenAddress} {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				host = address
+			}
+			if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) && !slices.Contains(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+		generated, err := utils.EnsureSelfSignedCertificate(certPath, keyPath, hosts)
+		if err != nil {
+			return nil, err
+		}
+		if generated {
+			s.logger.Info().Str("cert", certPath).Msgf("generated self-signed certificate for %s", strings.Join(hosts, ", "))
+		}
+	}
+	return utils.TLSConfig(certPath, keyPath, s.config.TLSClientCAFile)
+}
+
+func (s *API) corsOrigins() string {
+	if s.config.CORSOrigins == "" {
+		return "*"
+	}
+	return s.config.CORSOrigins
+}
+
+// scope returns the scope of the token required to make a request. The path is cleaned and lowercased
+// so that no spelling of a path that the router accepts requires a weaker scope.
+func scope(ctx *fiber.Ctx) auth.Scope {
+	path := strings.TrimSuffix(strings.ToLower(path.Clean("/"+ctx.Path())), "/")
+	switch {
+	case path == V1Path+"/health" || path == V1Path+"/swagger.json":
+		return auth.ScopeNone
+	case strings.HasPrefix(path, V1Path+"/machine/register"),
+		strings.HasPrefix(path, V1Path+"/machine/unregister"),
+		strings.HasPrefix(path, V1Path+"/webhooks"):
+		return auth.ScopeAdmin
+	case ctx.Method() == fiber.MethodGet || ctx.Method() == fiber.MethodHead:
+		return auth.ScopeRead
+	case path == V1Path+"/discovery", strings.HasPrefix(path, V1Path+"/machine/preflight/"):
+		// Discovery and preflight checks do not change anything
+		return auth.ScopeRead
+	default:
+		return auth.ScopeControl
+	}
+}
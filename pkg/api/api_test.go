@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/auth"
+)
+
+func TestScope(t *testing.T) {
+	logger := logging.Test(t, logging.Slog, t.Name())
+	store, err := auth.NewStore(filepath.Join(t.TempDir(), "tokens.json"))
+	require.NoError(t, err)
+	_, secret, err := store.Create("automation", auth.ScopeControl)
+	require.NoError(t, err)
+
+	ok := func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+	v1 := utils.DefaultFiberApp()
+	v1.Post("/machine/register", ok)
+	v1.Post("/machine/print/:id", ok)
+	v1.Get("/webhooks", ok)
+	app := utils.DefaultFiberApp()
+	app.Use(auth.Middleware(store, scope, logger))
+	app.Mount(V1Path, v1)
+
+	request := func(method string, target string) int {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set(auth.HeaderAPIKey, secret)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, request(fiber.MethodPost, "/v1/machine/print/0123456789abcdef"))
+	for _, target := range []string{
+		"/v1/machine/register",
+		"/v1/MACHINE/register",
+		"/v1/machine/Register",
+		"/V1/machine/register/",
+		"/v1//machine/./register",
+	} {
+		require.NotEqual(t, http.StatusOK, request(fiber.MethodPost, target), target)
+	}
+	require.Equal(t, http.StatusForbidden, request(fiber.MethodPost, "/v1/MACHINE/register"))
+	require.Equal(t, http.StatusForbidden, request(fiber.MethodGet, "/v1/WEBHOOKS"))
+	require.Equal(t, http.StatusForbidden, request(fiber.MethodGet, "/v1/webhooks"))
+}
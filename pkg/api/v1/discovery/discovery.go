@@ -42,7 +42,7 @@ func (a *Discovery) init() {
 func (a *Discovery) Discovery(ctx *fiber.Ctx) error {
 	a.logger.Debug().Msgf("received Discovery request from %s", ctx.IP())
 
-	discoveries, err := sdcp.Discover(a.logger, ctx.Context())
+	discoveries, err := sdcp.Discover(a.logger, ctx.UserContext())
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).SendString(err.Error())
 	}
@@ -47,6 +47,33 @@ const docTemplateapi = `{
                 }
             }
         },
+        "/events": {
+            "get": {
+                "description": "Streams status, attributes, connection state, error, notice, print and discovery events for machines as Server-Sent Events",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated list of machine IDs to stream events for, defaults to all machines",
+                        "name": "machines",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
                 "description": "Returns the health and status of the various services that make up the API.",
@@ -75,9 +102,9 @@ const docTemplateapi = `{
                 }
             }
         },
-        "/status": {
-            "post": {
-                "description": "Refreshes the status of a machine",
+        "/machine": {
+            "get": {
+                "description": "Lists every registered machine along with a summary of its status",
                 "consumes": [
                     "application/json"
                 ],
@@ -85,24 +112,82 @@ const docTemplateapi = `{
                     "application/json"
                 ],
                 "tags": [
-                    "discovery"
+                    "machine"
                 ],
                 "parameters": [
                     {
-                        "description": "Status Refresh Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Comma separated tags every listed machine must have",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Machine model",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "connecting",
+                            "online",
+                            "offline"
+                        ],
+                        "type": "string",
+                        "description": "Connection state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "idle",
+                            "printing",
+                            "file_transferring",
+                            "exposure_testing",
+                            "devices_testing"
+                        ],
+                        "type": "string",
+                        "description": "Machine status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.StatusRefreshRequest"
+                            "$ref": "#/definitions/models.MachineListResponse"
                         }
                     }
+                }
+            }
+        },
+        "/machine/attributes/{id}": {
+            "get": {
+                "description": "Retrieves the attributes of a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.MachineAttributesResponse"
                         }
                     },
                     "400": {
@@ -111,6 +196,12 @@ const docTemplateapi = `{
                             "type": "string"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -118,67 +209,4283 @@ const docTemplateapi = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.DiscoveryData": {
-            "type": "object",
-            "properties": {
-                "BrandName": {
-                    "description": "Brand Name",
-                    "type": "string"
-                },
-                "FirmwareVersion": {
-                    "description": "Firmware Version",
-                    "type": "string"
-                },
-                "MachineModel": {
-                    "description": "Machine Model",
-                    "type": "string"
-                },
-                "MachineName": {
-                    "description": "Machine Name",
-                    "type": "string"
-                },
-                "MachineID": {
-                    "description": "Motherboard ID (16-bit)",
-                    "type": "string"
-                },
-                "MachineIP": {
-                    "description": "Motherboard IP Address",
-                    "type": "string"
-                },
-                "ProtocolVersion": {
-                    "description": "Protocol Version",
-                    "type": "string"
+            },
+            "post": {
+                "description": "Refreshes and retrieves the attributes of a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineAttributesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "models.DiscoveryResponse": {
-            "type": "object",
-            "properties": {
-                "discovered": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.DiscoveryData"
+        "/machine/bulk/{action}": {
+            "post": {
+                "description": "Applies an action to every selected machine concurrently. Machines that the action does not apply to,\nsuch as machines that are not printing when pausing, are skipped. Time-lapse actions only succeed\nonce the machine confirms the change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "enum": [
+                            "refresh",
+                            "pause",
+                            "resume",
+                            "enable-timelapse",
+                            "disable-timelapse"
+                        ],
+                        "type": "string",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Machine Bulk Request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineBulkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineBulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "models.HealthResponse": {
-            "type": "object"
-        },
-        "models.StatusRefreshRequest": {
-            "type": "object",
-            "properties": {
-                "machine_id": {
-                    "type": "string"
-                },
-                "machine_ip": {
-                    "type": "string"
+        "/machine/files/{id}": {
+            "get": {
+                "description": "Lists the files on the storage of a machine, descending into folders recursively",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path to list, defaults to /local/",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether to descend into folders, defaults to true",
+                        "name": "recursive",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineFilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
-            }
+            },
+            "post": {
+                "description": "Uploads a file to the local storage of a machine",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineFileTransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes files and folders from the storage of a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Machine Delete Files Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineDeleteFilesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineDeleteFilesResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineDeleteFilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/files/{id}/transfer": {
+            "get": {
+                "description": "Retrieves the progress of the file upload in progress on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineFileTransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels the file upload in progress on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/history/{id}": {
+            "get": {
+                "description": "Retrieves a page of the print history of a machine, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of tasks to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of tasks to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/history/{id}/{task}": {
+            "get": {
+                "description": "Retrieves the details of a single task from the print history of a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task",
+                        "name": "task",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/log/{id}": {
+            "get": {
+                "description": "Retrieves the most recent errors and notices reported by a machine, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineEventLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/preflight/{id}": {
+            "post": {
+                "description": "Checks whether a sliced file can be uploaded to and printed by a machine, without uploading it",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to check",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePreflightResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/print/{id}": {
+            "post": {
+                "description": "Starts printing a file on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Machine Start Print Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineStartPrintRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePrintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stops the current print on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePrintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/print/{id}/pause": {
+            "post": {
+                "description": "Pauses the current print on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePrintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/print/{id}/resume": {
+            "post": {
+                "description": "Resumes the current paused print on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePrintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/print/{id}/skip-preheat": {
+            "post": {
+                "description": "Skips the preheating phase of the current print on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePrintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/print/{id}/stop-feeding": {
+            "post": {
+                "description": "Stops the automatic material feeding on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachinePrintResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/register": {
+            "post": {
+                "description": "Registers a new machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "description": "Machine Register Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineRegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/settings/{id}": {
+            "patch": {
+                "description": "Applies several settings to a machine, waiting for the machine to confirm each one.\nReports which settings took effect, failing only if none of them did.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Machine Settings Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineSettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineSettingsResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineSettingsResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineSettingsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/status/{id}": {
+            "get": {
+                "description": "Retrieves the status of a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Refreshes and retrieves the status of a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/unregister/{id}": {
+            "post": {
+                "description": "Unregisters an existing machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/machine/video/{id}": {
+            "post": {
+                "description": "Enables Video streaming on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineVideoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Disables Video streaming on a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machine"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineVideoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance": {
+            "get": {
+                "description": "Retrieves the most recent maintenance alerts of every machine, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceAlertsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance/{id}": {
+            "get": {
+                "description": "Retrieves the wear and forecast remaining life of the consumables of a machine, along with its maintenance alerts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance/{id}/replace": {
+            "post": {
+                "description": "Records that a consumable of a machine was replaced, resetting its wear",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Maintenance Replace Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceReplaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue": {
+            "get": {
+                "description": "Lists every job in the queue in the order they are scheduled in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Submits a sliced file to the queue, to be printed by the next idle machine that satisfies its constraints",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to print",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jobs with a higher priority are scheduled first",
+                        "name": "priority",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of times the job is attempted before it fails",
+                        "name": "max_attempts",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Submit the job held, so that it is not scheduled until it is released",
+                        "name": "hold",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Machine model the job must be printed on",
+                        "name": "model",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resolution of the machine the job must be printed on",
+                        "name": "resolution",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File type the machine must support, defaults to the file extension",
+                        "name": "file_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "IDs of the machines the job may be printed on",
+                        "name": "machines",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tags the machine the job is printed on must have",
+                        "name": "tags",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}": {
+            "get": {
+                "description": "Retrieves a job in the queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a job that is not assigned to a machine from the queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes the priority of a job in the queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Queue Update Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/cancel": {
+            "post": {
+                "description": "Cancels a job, stopping its upload or print if it is assigned to a machine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/hold": {
+            "post": {
+                "description": "Holds a queued job, so that it is not scheduled until it is released",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/release": {
+            "post": {
+                "description": "Releases a held job, so that it can be scheduled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/retry": {
+            "post": {
+                "description": "Queues a failed or cancelled job again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/thumbnail": {
+            "get": {
+                "description": "Retrieves the preview image of the sliced file of a job in the queue",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Lists every webhook, without their secrets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhooksResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a webhook that is sent print lifecycle and machine connection events. Payloads are signed with the secret of the webhook, which is only returned by this request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "parameters": [
+                    {
+                        "description": "Webhook Create Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Retrieves a webhook, without its secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a webhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Retrieves the most recent deliveries to a webhook, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "maintenance.Consumable": {
+            "type": "string",
+            "enum": [
+                "release_film",
+                "screen"
+            ],
+            "x-enum-comments": {
+                "ConsumableReleaseFilm": "FEP release film, worn by every layer lift",
+                "ConsumableScreen": "Exposure LCD, worn by exposure time"
+            },
+            "x-enum-varnames": [
+                "ConsumableReleaseFilm",
+                "ConsumableScreen"
+            ]
+        },
+        "maintenance.Level": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "ok",
+                "warning",
+                "critical"
+            ],
+            "x-enum-comments": {
+                "LevelUnknown": "The service life of the consumable is not known"
+            },
+            "x-enum-varnames": [
+                "LevelUnknown",
+                "LevelOK",
+                "LevelWarning",
+                "LevelCritical"
+            ]
+        },
+        "models.DiscoveryData": {
+            "type": "object",
+            "properties": {
+                "BrandName": {
+                    "description": "Brand Name",
+                    "type": "string"
+                },
+                "FirmwareVersion": {
+                    "description": "Firmware Version",
+                    "type": "string"
+                },
+                "MachineID": {
+                    "description": "Motherboard ID (16-bit)",
+                    "type": "string"
+                },
+                "MachineIP": {
+                    "description": "Motherboard IP Address",
+                    "type": "string"
+                },
+                "MachineModel": {
+                    "description": "Machine Model",
+                    "type": "string"
+                },
+                "MachineName": {
+                    "description": "Machine Name",
+                    "type": "string"
+                },
+                "ProtocolVersion": {
+                    "description": "Protocol Version",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DiscoveryResponse": {
+            "type": "object",
+            "properties": {
+                "discovered": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiscoveryData"
+                    }
+                }
+            }
+        },
+        "models.Event": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/sdcp.Attributes"
+                },
+                "discovery": {
+                    "$ref": "#/definitions/models.EventDiscovery"
+                },
+                "error": {
+                    "$ref": "#/definitions/sdcp.ErrorData"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "notice": {
+                    "$ref": "#/definitions/sdcp.NotificationData"
+                },
+                "print": {
+                    "$ref": "#/definitions/models.EventPrint"
+                },
+                "state": {
+                    "$ref": "#/definitions/sdcp.ConnectionState"
+                },
+                "status": {
+                    "$ref": "#/definitions/sdcp.Status"
+                },
+                "time": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/sdcp.EventType"
+                }
+            }
+        },
+        "models.EventDiscovery": {
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string"
+                },
+                "machine_model": {
+                    "type": "string"
+                },
+                "machine_name": {
+                    "type": "string"
+                },
+                "previous_ip": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/sdcp.DiscoveryEventType"
+                }
+            }
+        },
+        "models.EventPrint": {
+            "type": "object",
+            "properties": {
+                "current_layer": {
+                    "type": "integer"
+                },
+                "error": {
+                    "$ref": "#/definitions/sdcp.PrintInfoError"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "total_layer": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/sdcp.PrintEventType"
+                }
+            }
+        },
+        "models.HealthResponse": {
+            "type": "object"
+        },
+        "models.MachineAttributesResponse": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/sdcp.Attributes"
+                }
+            }
+        },
+        "models.MachineBulkRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/sdcp.ConnectionState"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.MachineBulkResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineBulkResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MachineBulkResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "boolean"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.MachineDeleteFilesRequest": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "folders": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.MachineDeleteFilesResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.MachineEventLogResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Event"
+                    }
+                }
+            }
+        },
+        "models.MachineFile": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "storage_type": {
+                    "$ref": "#/definitions/sdcp.StorageType"
+                },
+                "type": {
+                    "$ref": "#/definitions/sdcp.FileType"
+                }
+            }
+        },
+        "models.MachineFileTransfer": {
+            "type": "object",
+            "properties": {
+                "filename": {
+                    "type": "string"
+                },
+                "md5": {
+                    "type": "string"
+                },
+                "sent": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "description": "Unix time in milliseconds",
+                    "type": "integer"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MachineFileTransferResponse": {
+            "type": "object",
+            "properties": {
+                "transfer": {
+                    "$ref": "#/definitions/models.MachineFileTransfer"
+                }
+            }
+        },
+        "models.MachineFilesResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineFile"
+                    }
+                },
+                "storage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineStorage"
+                    }
+                }
+            }
+        },
+        "models.MachineHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineTask"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MachineListResponse": {
+            "type": "object",
+            "properties": {
+                "machines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineSummary"
+                    }
+                }
+            }
+        },
+        "models.MachinePreflightFile": {
+            "type": "object",
+            "properties": {
+                "exposure_time": {
+                    "type": "number"
+                },
+                "file_type": {
+                    "$ref": "#/definitions/sdcp.SupportedFileType"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "number"
+                },
+                "layer_count": {
+                    "type": "integer"
+                },
+                "layer_height": {
+                    "type": "number"
+                },
+                "machine_name": {
+                    "type": "string"
+                },
+                "print_time": {
+                    "type": "integer"
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.MachinePreflightResponse": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "$ref": "#/definitions/models.MachinePreflightFile"
+                },
+                "passed": {
+                    "type": "boolean"
+                },
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/preflight.Problem"
+                    }
+                }
+            }
+        },
+        "models.MachinePrintResponse": {
+            "type": "object",
+            "properties": {
+                "ack": {
+                    "$ref": "#/definitions/sdcp.ControlAck"
+                }
+            }
+        },
+        "models.MachineRegisterRequest": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "machine_ip": {
+                    "type": "string"
+                },
+                "machine_port": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.MachineSettingResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "setting": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MachineSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "time_lapse": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.MachineSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineSettingResult"
+                    }
+                }
+            }
+        },
+        "models.MachineStartPrintRequest": {
+            "type": "object",
+            "properties": {
+                "filename": {
+                    "type": "string"
+                },
+                "start_layer": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MachineStatusResponse": {
+            "type": "object",
+            "properties": {
+                "state": {
+                    "$ref": "#/definitions/sdcp.ConnectionState"
+                },
+                "status": {
+                    "$ref": "#/definitions/sdcp.Status"
+                }
+            }
+        },
+        "models.MachineStorage": {
+            "type": "object",
+            "properties": {
+                "storage_type": {
+                    "$ref": "#/definitions/sdcp.StorageType"
+                },
+                "total_size": {
+                    "type": "integer"
+                },
+                "used_size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MachineSummary": {
+            "type": "object",
+            "properties": {
+                "current_layer": {
+                    "type": "integer"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "print_status": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "number"
+                },
+                "state": {
+                    "$ref": "#/definitions/sdcp.ConnectionState"
+                },
+                "status": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "time_lapse_status": {
+                    "$ref": "#/definitions/sdcp.TimeLapseStatus"
+                },
+                "total_layer": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MachineTask": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task": {
+                    "$ref": "#/definitions/sdcp.TaskDetails"
+                }
+            }
+        },
+        "models.MachineTaskResponse": {
+            "type": "object",
+            "properties": {
+                "task": {
+                    "$ref": "#/definitions/models.MachineTask"
+                }
+            }
+        },
+        "models.MachineVideoResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/sdcp.EnableDisableVideoStreamResponse"
+                }
+            }
+        },
+        "models.MaintenanceAlert": {
+            "type": "object",
+            "properties": {
+                "machine_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "integer"
+                },
+                "wear": {
+                    "$ref": "#/definitions/models.MaintenanceWear"
+                }
+            }
+        },
+        "models.MaintenanceAlertsResponse": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MaintenanceAlert"
+                    }
+                }
+            }
+        },
+        "models.MaintenanceReplaceRequest": {
+            "type": "object",
+            "properties": {
+                "consumable": {
+                    "$ref": "#/definitions/maintenance.Consumable"
+                }
+            }
+        },
+        "models.MaintenanceResponse": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MaintenanceAlert"
+                    }
+                },
+                "wear": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MaintenanceWear"
+                    }
+                }
+            }
+        },
+        "models.MaintenanceWear": {
+            "type": "object",
+            "properties": {
+                "consumable": {
+                    "$ref": "#/definitions/maintenance.Consumable"
+                },
+                "exhausted_at": {
+                    "type": "integer"
+                },
+                "fraction": {
+                    "type": "number"
+                },
+                "level": {
+                    "$ref": "#/definitions/maintenance.Level"
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "rate_per_day": {
+                    "type": "number"
+                },
+                "remaining": {
+                    "type": "number"
+                },
+                "replaced_at": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.QueueJob": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "constraints": {
+                    "$ref": "#/definitions/queue.Constraints"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "integer"
+                },
+                "state": {
+                    "$ref": "#/definitions/queue.State"
+                },
+                "task_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.QueueJobResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/models.QueueJob"
+                }
+            }
+        },
+        "models.QueueResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.QueueJob"
+                    }
+                }
+            }
+        },
+        "models.QueueUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "priority": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Event"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookCreateRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "Events the webhook is sent, every event if empty",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Event"
+                    }
+                },
+                "secret": {
+                    "description": "Secret the payloads are signed with, generated if empty",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookCreateResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Only ever returned when the webhook is created",
+                    "type": "string"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/models.Webhook"
+                }
+            }
+        },
+        "models.WebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/webhook.Event"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "boolean"
+                },
+                "time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "webhook": {
+                    "$ref": "#/definitions/models.Webhook"
+                }
+            }
+        },
+        "models.WebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Webhook"
+                    }
+                }
+            }
+        },
+        "preflight.Code": {
+            "type": "string",
+            "enum": [
+                "machine_offline",
+                "machine_busy",
+                "unknown_attributes",
+                "unknown_format",
+                "unsupported_file_type",
+                "resolution_mismatch",
+                "model_mismatch",
+                "display_mismatch",
+                "height_exceeded",
+                "insufficient_storage",
+                "usb_disk_disconnected",
+                "device_fault"
+            ],
+            "x-enum-varnames": [
+                "CodeMachineOffline",
+                "CodeMachineBusy",
+                "CodeUnknownAttributes",
+                "CodeUnknownFormat",
+                "CodeUnsupportedFileType",
+                "CodeResolutionMismatch",
+                "CodeModelMismatch",
+                "CodeDisplayMismatch",
+                "CodeHeightExceeded",
+                "CodeInsufficientStorage",
+                "CodeUsbDiskDisconnected",
+                "CodeDeviceFault"
+            ]
+        },
+        "preflight.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/preflight.Code"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "severity": {
+                    "$ref": "#/definitions/preflight.Severity"
+                }
+            }
+        },
+        "preflight.Severity": {
+            "type": "string",
+            "enum": [
+                "error",
+                "warning"
+            ],
+            "x-enum-comments": {
+                "SeverityError": "The file will be rejected by the machine, or the print will fail",
+                "SeverityWarning": "The file may not print as expected"
+            },
+            "x-enum-varnames": [
+                "SeverityError",
+                "SeverityWarning"
+            ]
+        },
+        "queue.Constraints": {
+            "type": "object",
+            "properties": {
+                "file_type": {
+                    "description": "Must be in Attributes.SupportFileType, defaults to the file extension",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.SupportedFileType"
+                        }
+                    ]
+                },
+                "machines": {
+                    "description": "IDs of the machines the job may be scheduled on",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "description": "Must match Attributes.MachineModel",
+                    "type": "string"
+                },
+                "resolution": {
+                    "description": "Must match Attributes.Resolution",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Tags every machine the job is scheduled on must have",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "queue.State": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "held",
+                "uploading",
+                "printing",
+                "completed",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-comments": {
+                "StateHeld": "Will not be scheduled until it is released",
+                "StatePrinting": "Being printed by the machine it was scheduled on",
+                "StateQueued": "Waiting for a compatible machine to become idle",
+                "StateUploading": "Being uploaded to the machine it was scheduled on"
+            },
+            "x-enum-varnames": [
+                "StateQueued",
+                "StateHeld",
+                "StateUploading",
+                "StatePrinting",
+                "StateCompleted",
+                "StateFailed",
+                "StateCancelled"
+            ]
+        },
+        "sdcp.Attributes": {
+            "type": "object",
+            "properties": {
+                "BrandName": {
+                    "description": "Brand Name",
+                    "type": "string"
+                },
+                "CameraStatus": {
+                    "description": "Camera Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.CameraStatus"
+                        }
+                    ]
+                },
+                "Capabilities": {
+                    "description": "Supported Sub-protocols on the Motherboard",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sdcp.Capabilities"
+                    }
+                },
+                "DevicesStatus": {
+                    "description": "Device Self-Check Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.DeviceStatus"
+                        }
+                    ]
+                },
+                "FirmwareVersion": {
+                    "description": "Firmware Version",
+                    "type": "string"
+                },
+                "MachineName": {
+                    "description": "Machine Model",
+                    "type": "string"
+                },
+                "MainboardID": {
+                    "description": "Motherboard ID (16-bit)",
+                    "type": "string"
+                },
+                "MainboardIP": {
+                    "description": "Motherboard IP Address",
+                    "type": "string"
+                },
+                "MaximumVideoStreamAllowed": {
+                    "description": "Maximum Number of Connections for Video Streams",
+                    "type": "integer"
+                },
+                "Name": {
+                    "description": "Machine Name",
+                    "type": "string"
+                },
+                "NetworkStatus": {
+                    "description": "Network Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.NetworkStatus"
+                        }
+                    ]
+                },
+                "NumberOfVideoStreamConnected": {
+                    "description": "Number of Connected Video Streams",
+                    "type": "integer"
+                },
+                "ProtocolVersion": {
+                    "description": "Protocol Version",
+                    "type": "string"
+                },
+                "ReleaseFilmMax": {
+                    "description": "Maximum number of uses (service life) for the release film",
+                    "type": "integer"
+                },
+                "RemainingMemory": {
+                    "description": "Remaining File Storage Space Size (bits)",
+                    "type": "integer"
+                },
+                "Resolution": {
+                    "description": "Resolution",
+                    "type": "string"
+                },
+                "SupportFileType": {
+                    "description": "Supported File Types",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sdcp.SupportedFileType"
+                    }
+                },
+                "TLPInterLayers": {
+                    "description": "Time-lapse photography shooting interval layers",
+                    "type": "integer"
+                },
+                "TLPNoCapPos": {
+                    "description": "Model height threshold for not performing time-lapse photography (millimeters)",
+                    "type": "number"
+                },
+                "TLPStartCapPos": {
+                    "description": "The print height at which time-lapse photography begins (millimeters)",
+                    "type": "number"
+                },
+                "TempOfUVLEDMax": {
+                    "description": "Maximum operating temperature for UVLED (Celsius)",
+                    "type": "number"
+                },
+                "UsbDiskStatus": {
+                    "description": "USB Drive Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.UsbDiskStatus"
+                        }
+                    ]
+                },
+                "XYZsize": {
+                    "description": "Maximum printing dimensions in the XYZ directions of the machine (millimeters)",
+                    "type": "string"
+                }
+            }
+        },
+        "sdcp.CameraStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "CameraStatusDisconnected",
+                "CameraStatusConnected"
+            ]
+        },
+        "sdcp.Capabilities": {
+            "type": "string",
+            "enum": [
+                "FILE_TRANSFER",
+                "PRINT_CONTROL",
+                "VIDEO_STREAM"
+            ],
+            "x-enum-varnames": [
+                "CapabilitiesFileTransfer",
+                "CapabilitiesPrintControl",
+                "CapabilitiesVideoStream"
+            ]
+        },
+        "sdcp.ConnectionState": {
+            "type": "string",
+            "enum": [
+                "connecting",
+                "online",
+                "offline"
+            ],
+            "x-enum-varnames": [
+                "ConnectionStateConnecting",
+                "ConnectionStateOnline",
+                "ConnectionStateOffline"
+            ]
+        },
+        "sdcp.ControlAck": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6,
+                7
+            ],
+            "x-enum-comments": {
+                "ControlAckBusy": "Busy",
+                "ControlAckFileIOFailed": "File Read Failed",
+                "ControlAckInvalidResolution": "Resolution Mismatch",
+                "ControlAckMd5FailFailed": "MD5 Verification Failed",
+                "ControlAckNotFound": "File Not Found",
+                "ControlAckOk": "OK",
+                "ControlAckUnknownFormat": "Unrecognized File Format",
+                "ControlAckUnknownModel": "Machine Model Mismatch"
+            },
+            "x-enum-varnames": [
+                "ControlAckOk",
+                "ControlAckBusy",
+                "ControlAckNotFound",
+                "ControlAckMd5FailFailed",
+                "ControlAckFileIOFailed",
+                "ControlAckInvalidResolution",
+                "ControlAckUnknownFormat",
+                "ControlAckUnknownModel"
+            ]
+        },
+        "sdcp.DeviceStatus": {
+            "type": "object",
+            "properties": {
+                "LCDStatus": {
+                    "description": "Exposure Screen Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.LCDStatus"
+                        }
+                    ]
+                },
+                "ReleaseFilmState": {
+                    "description": "Release Film Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.ReleaseFilmState"
+                        }
+                    ]
+                },
+                "RotateMotorStatus": {
+                    "description": "Rotary Axis Motor Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.RotateMotorStatus"
+                        }
+                    ]
+                },
+                "SgStatus": {
+                    "description": "Strain Gauge Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.SgStatus"
+                        }
+                    ]
+                },
+                "TempSensorStatusOfUVLED": {
+                    "description": "UVLED Temperature Sensor Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.TempSensorStatusOfUVLED"
+                        }
+                    ]
+                },
+                "XMotorStatus": {
+                    "description": "X-Axis Motor Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.XMotorStatus"
+                        }
+                    ]
+                },
+                "ZMotorStatus": {
+                    "description": "Z-Axis Motor Connection Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.ZMotorStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "sdcp.DiscoveryEventType": {
+            "type": "string",
+            "enum": [
+                "registered",
+                "address_changed"
+            ],
+            "x-enum-comments": {
+                "DiscoveryEventTypeAddressChanged": "A registered machine was discovered at a new IP address",
+                "DiscoveryEventTypeRegistered": "A discovered machine was registered"
+            },
+            "x-enum-varnames": [
+                "DiscoveryEventTypeRegistered",
+                "DiscoveryEventTypeAddressChanged"
+            ]
+        },
+        "sdcp.EnableDisableVideoStreamResponse": {
+            "type": "object",
+            "properties": {
+                "Ack": {
+                    "description": "Acknowledgement",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.StreamAck"
+                        }
+                    ]
+                },
+                "VideoUrl": {
+                    "description": "When opening the video stream, return the RTSP protocol address",
+                    "type": "string"
+                }
+            }
+        },
+        "sdcp.ErrorCode": {
+            "type": "integer",
+            "enum": [
+                1,
+                2
+            ],
+            "x-enum-comments": {
+                "ErrorCodeFormatFailed": "File format is incorrect",
+                "ErrorCodeMD5Failed": "File Transfer MD5 Check Failed"
+            },
+            "x-enum-varnames": [
+                "ErrorCodeMD5Failed",
+                "ErrorCodeFormatFailed"
+            ]
+        },
+        "sdcp.ErrorCodeData": {
+            "type": "object",
+            "properties": {
+                "ErrorCode": {
+                    "description": "Error Code",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.ErrorCode"
+                        }
+                    ]
+                }
+            }
+        },
+        "sdcp.ErrorData": {
+            "type": "object",
+            "properties": {
+                "Data": {
+                    "description": "Error Data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.ErrorCodeData"
+                        }
+                    ]
+                },
+                "MainboardID": {
+                    "description": "Motherboard ID (16-bit)",
+                    "type": "string"
+                },
+                "TimeStamp": {
+                    "description": "Timestamp",
+                    "type": "integer"
+                }
+            }
+        },
+        "sdcp.EventType": {
+            "type": "string",
+            "enum": [
+                "status",
+                "attributes",
+                "state",
+                "error",
+                "notice",
+                "print",
+                "discovery"
+            ],
+            "x-enum-varnames": [
+                "EventTypeStatus",
+                "EventTypeAttributes",
+                "EventTypeState",
+                "EventTypeError",
+                "EventTypeNotice",
+                "EventTypePrint",
+                "EventTypeDiscovery"
+            ]
+        },
+        "sdcp.FileType": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "FileTypeFolder",
+                "FileTypeFile"
+            ]
+        },
+        "sdcp.LCDStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "LCDStatusDisconnected",
+                "LCDStatusConnected"
+            ]
+        },
+        "sdcp.MachineStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-comments": {
+                "MachineStatusDevicesTesting": "Devices Testing",
+                "MachineStatusExposureTesting": "Exposure Testing",
+                "MachineStatusFileTransferring": "File Transferring",
+                "MachineStatusIdle": "Idle",
+                "MachineStatusPrinting": "Printing"
+            },
+            "x-enum-varnames": [
+                "MachineStatusIdle",
+                "MachineStatusPrinting",
+                "MachineStatusFileTransferring",
+                "MachineStatusExposureTesting",
+                "MachineStatusDevicesTesting"
+            ]
+        },
+        "sdcp.NetworkStatus": {
+            "type": "string",
+            "enum": [
+                "wlan",
+                "eth"
+            ],
+            "x-enum-varnames": [
+                "NetworkStatusWlan",
+                "NetworkStatusEth"
+            ]
+        },
+        "sdcp.NotificationData": {
+            "type": "object",
+            "properties": {
+                "Data": {
+                    "description": "Notification Type Data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.NotificationTypeData"
+                        }
+                    ]
+                },
+                "MainboardID": {
+                    "description": "Motherboard ID (16-bit)",
+                    "type": "string"
+                },
+                "TimeStamp": {
+                    "description": "Timestamp",
+                    "type": "integer"
+                }
+            }
+        },
+        "sdcp.NotificationType": {
+            "type": "integer",
+            "enum": [
+                1
+            ],
+            "x-enum-varnames": [
+                "HistorySynchronizationSuccessful"
+            ]
+        },
+        "sdcp.NotificationTypeData": {
+            "type": "object",
+            "properties": {
+                "Message": {
+                    "description": "Can be a string, can be JSON",
+                    "type": "string"
+                },
+                "Type": {
+                    "description": "Notification Type",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.NotificationType"
+                        }
+                    ]
+                }
+            }
+        },
+        "sdcp.PrintEventType": {
+            "type": "string",
+            "enum": [
+                "started",
+                "paused",
+                "resumed",
+                "completed",
+                "stopped"
+            ],
+            "x-enum-comments": {
+                "PrintEventTypeStopped": "Stopped by a user or by the machine, the task details record which"
+            },
+            "x-enum-varnames": [
+                "PrintEventTypeStarted",
+                "PrintEventTypePaused",
+                "PrintEventTypeResumed",
+                "PrintEventTypeCompleted",
+                "PrintEventTypeStopped"
+            ]
+        },
+        "sdcp.PrintInfo": {
+            "type": "object",
+            "properties": {
+                "CurrentLayer": {
+                    "description": "Current Printing Layer",
+                    "type": "integer"
+                },
+                "CurrentTicks": {
+                    "description": "Current Print Time (milliseconds)",
+                    "type": "integer"
+                },
+                "ErrorNumber": {
+                    "description": "Error Number",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.PrintInfoError"
+                        }
+                    ]
+                },
+                "Filename": {
+                    "description": "Print File Name",
+                    "type": "string"
+                },
+                "Status": {
+                    "description": "Printing Sub-status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.PrintInfoStatus"
+                        }
+                    ]
+                },
+                "TaskId": {
+                    "description": "Current Task ID",
+                    "type": "string"
+                },
+                "TotalLayer": {
+                    "description": "Total Number of Print Layers",
+                    "type": "integer"
+                },
+                "TotalTicks": {
+                    "description": "Estimated Total Print Time (milliseconds)",
+                    "type": "integer"
+                }
+            }
+        },
+        "sdcp.PrintInfoError": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5
+            ],
+            "x-enum-comments": {
+                "PrintInfoErrorCheck": "File MD5 Check Failed",
+                "PrintInfoErrorFileIO": "File Read Failed",
+                "PrintInfoErrorInvalidResolution": "Resolution Mismatch",
+                "PrintInfoErrorNone": "Normal",
+                "PrintInfoErrorUnknownFormat": "Format Mismatch",
+                "PrintInfoErrorUnknownModel": "Machine Model Mismatch"
+            },
+            "x-enum-varnames": [
+                "PrintInfoErrorNone",
+                "PrintInfoErrorCheck",
+                "PrintInfoErrorFileIO",
+                "PrintInfoErrorInvalidResolution",
+                "PrintInfoErrorUnknownFormat",
+                "PrintInfoErrorUnknownModel"
+            ]
+        },
+        "sdcp.PrintInfoStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6,
+                7,
+                8,
+                9,
+                10
+            ],
+            "x-enum-comments": {
+                "PrintInfoStatusComplete": "Complete",
+                "PrintInfoStatusDropping": "Dropping",
+                "PrintInfoStatusExposing": "Exposing",
+                "PrintInfoStatusFileChecking": "File Checking",
+                "PrintInfoStatusHoming": "Homing",
+                "PrintInfoStatusIdle": "Idle",
+                "PrintInfoStatusLifting": "Lifting",
+                "PrintInfoStatusPaused": "Paused",
+                "PrintInfoStatusPausing": "Pausing",
+                "PrintInfoStatusStopped": "Stopped",
+                "PrintInfoStatusStopping": "Stopping"
+            },
+            "x-enum-varnames": [
+                "PrintInfoStatusIdle",
+                "PrintInfoStatusHoming",
+                "PrintInfoStatusDropping",
+                "PrintInfoStatusExposing",
+                "PrintInfoStatusLifting",
+                "PrintInfoStatusPausing",
+                "PrintInfoStatusPaused",
+                "PrintInfoStatusStopping",
+                "PrintInfoStatusStopped",
+                "PrintInfoStatusComplete",
+                "PrintInfoStatusFileChecking"
+            ]
+        },
+        "sdcp.ReleaseFilmState": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "ReleaseFilmStateAbnormal",
+                "ReleaseFilmStateNormal"
+            ]
+        },
+        "sdcp.RotateMotorStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "RotateMotorStatusDisconnected",
+                "RotateMotorStatusConnected"
+            ]
+        },
+        "sdcp.SgStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "SgStatusDisconnected",
+                "SgStatusNormal",
+                "SgStatusCalibrationFailed"
+            ]
+        },
+        "sdcp.Status": {
+            "type": "object",
+            "properties": {
+                "CurrentStatus": {
+                    "description": "Current Machine Status",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sdcp.MachineStatus"
+                    }
+                },
+                "PreviousStatus": {
+                    "description": "Previous Machine Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.MachineStatus"
+                        }
+                    ]
+                },
+                "PrintInfo": {
+                    "$ref": "#/definitions/sdcp.PrintInfo"
+                },
+                "PrintScreen": {
+                    "description": "Total Exposure Screen Usage Time (seconds)",
+                    "type": "number"
+                },
+                "ReleaseFilm": {
+                    "description": "Total Release Film Usage Count",
+                    "type": "integer"
+                },
+                "TempOfBox": {
+                    "description": "Current Enclosure Temperature (Celsius)",
+                    "type": "number"
+                },
+                "TempOfUVLED": {
+                    "description": "Current UVLED Temperature (Celsius)",
+                    "type": "number"
+                },
+                "TempTargetBox": {
+                    "description": "Target Enclosure Temperature (Celsius)",
+                    "type": "number"
+                },
+                "TimeLapseStatus": {
+                    "description": "Time-lapse Photography Switch Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.TimeLapseStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "sdcp.StorageType": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "StorageTypeInternal",
+                "StorageTypeExternal"
+            ]
+        },
+        "sdcp.StreamAck": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-comments": {
+                "StreamAckLimit": "Exceeded maximum simultaneous streaming limit",
+                "StreamAckNotExist": "Camera does not exist",
+                "StreamAckSuccess": "Success",
+                "StreamAckUnknown": "Unknown error"
+            },
+            "x-enum-varnames": [
+                "StreamAckSuccess",
+                "StreamAckLimit",
+                "StreamAckNotExist",
+                "StreamAckUnknown"
+            ]
+        },
+        "sdcp.SupportedFileType": {
+            "type": "string",
+            "enum": [
+                "CTB",
+                "GOO"
+            ],
+            "x-enum-varnames": [
+                "SupportedFileTypeCTB",
+                "SupportedFileTypeGOO"
+            ]
+        },
+        "sdcp.TaskDetails": {
+            "type": "object",
+            "properties": {
+                "AlreadyPrintLayer": {
+                    "description": "Printed Layer Count",
+                    "type": "integer"
+                },
+                "BeginTime": {
+                    "description": "Start Time (Timestamp in Seconds)",
+                    "type": "integer"
+                },
+                "CurrentLayerTalVolume": {
+                    "description": "Total Volume of Printed Layers (milliliters)",
+                    "type": "number"
+                },
+                "EndTime": {
+                    "description": "End Time (Timestamp in Seconds)",
+                    "type": "integer"
+                },
+                "ErrorStatusReason": {
+                    "description": "Status Code",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.TaskError"
+                        }
+                    ]
+                },
+                "MD5": {
+                    "description": "MD5 of the Sliced File",
+                    "type": "string"
+                },
+                "SliceInformation": {
+                    "description": "Slice Information",
+                    "type": "object"
+                },
+                "TaskId": {
+                    "description": "Task ID",
+                    "type": "string"
+                },
+                "TaskName": {
+                    "description": "Task Name",
+                    "type": "string"
+                },
+                "TaskStatus": {
+                    "description": "Task Status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.TaskStatus"
+                        }
+                    ]
+                },
+                "Thumbnail": {
+                    "description": "Thumbnail Address",
+                    "type": "string"
+                },
+                "TimeLapseVideoStatus": {
+                    "description": "Time-lapse photography status",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sdcp.TimeLapseVideoStatus"
+                        }
+                    ]
+                },
+                "TimeLapseVideoUrl": {
+                    "description": "URL for the time-lapse photography video",
+                    "type": "string"
+                }
+            }
+        },
+        "sdcp.TaskError": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6,
+                7,
+                8,
+                9,
+                10,
+                11,
+                12,
+                13,
+                14,
+                15,
+                16,
+                17,
+                18,
+                19,
+                20,
+                21,
+                22,
+                23,
+                24,
+                25,
+                26,
+                27,
+                28,
+                29,
+                30,
+                31,
+                32,
+                33,
+                34
+            ],
+            "x-enum-comments": {
+                "TaskErrorAicModelNone": "No model detected, please troubleshoot",
+                "TaskErrorAicModelWarp": "Warping of the model detected, please investigate",
+                "TaskErrorBottleDisconnect": "Please ensure that the automatic material extraction/feeding machine is correctly installed and the data cable is connected",
+                "TaskErrorCalibrateFailed": "Strain Gauge Calibration Failed",
+                "TaskErrorCameraError": "Camera Error. Please check if the camera is properly connected, or you can also disable this feature to continue printing",
+                "TaskErrorCheckAutoResinFeeder": "lease check the installation of the \"automatic material extraction / feeding machine\"",
+                "TaskErrorContainerResinLow": "The resin in the container is running low. Add more resin to automatically close this notification, or click \"Stop Auto Feeding\" to continue printing",
+                "TaskErrorDisconnectApp": "This printer is not bound to an app. To perform time-lapse photography, please first enable the remote control feature, or you can also disable this feature to continue printing",
+                "TaskErrorError": "Printing Exception",
+                "TaskErrorFeedTimeout": "Automatic material extraction timeout, please check if the resin tube is blocked",
+                "TaskErrorFileError": "Error File",
+                "TaskErrorForeignBody": "Foreign Object Detected",
+                "TaskErrorHomeFailed": "Home position calibration failed, please check if the motor or limit switch is functioning properly",
+                "TaskErrorHomeFailedX": "Detection of X-axis motor anomaly, printing has been stopped",
+                "TaskErrorHomeFailedY": "Deprecated",
+                "TaskErrorHomeFailedZ": "Detection of Z-axis motor anomaly, printing has been stopped",
+                "TaskErrorLcdDetFailed": "LCD Screen Connection Abnormal",
+                "TaskErrorLevelFailed": "Auto-leveling Failed",
+                "TaskErrorMoveAbnormal": "Motor Movement Abnormality",
+                "TaskErrorNetworkError": "Network Connection Error. Please check if your network connection is stable, or you can also disable this feature to continue printing",
+                "TaskErrorOk": "Normal",
+                "TaskErrorPlatFailed": "A model is detected on the platform; please clean it and then restart printing",
+                "TaskErrorProbeFail": "No Resin Detected",
+                "TaskErrorReleaseFailed": "Model Detachment Detected",
+                "TaskErrorReleaseOvercount": "The cumulative release film usage has reached the maximum value",
+                "TaskErrorResinAbnormalHigh": "The resin level has been detected to exceed the maximum value, and printing has been stopped",
+                "TaskErrorResinAbnormalLow": "Resin level detected as too low, printing has been stopped",
+                "TaskErrorResinLack": "Resin Level Low Detected",
+                "TaskErrorResinOver": "The volume of resin required by the model exceeds the maximum capacity of the resin vat",
+                "TaskErrorServerConnectFailed": "Server Connection Failed. Please contact our customer support, or you can also disable this feature to continue printing",
+                "TaskErrorSgOffline": "Strain Gauge Not Connected",
+                "TaskErrorTankTempSensorError": "Resin vat temperature sensor indicates an over-temperature condition",
+                "TaskErrorTankTempSensorOffline": "Resin vat temperature sensor not connected",
+                "TaskErrorTempError": "Over-temperature",
+                "TaskErrorUdiskRemove": "USB drive detected as removed, printing has been stopped"
+            },
+            "x-enum-varnames": [
+                "TaskErrorOk",
+                "TaskErrorTempError",
+                "TaskErrorCalibrateFailed",
+                "TaskErrorResinLack",
+                "TaskErrorResinOver",
+                "TaskErrorProbeFail",
+                "TaskErrorForeignBody",
+                "TaskErrorLevelFailed",
+                "TaskErrorReleaseFailed",
+                "TaskErrorSgOffline",
+                "TaskErrorLcdDetFailed",
+                "TaskErrorReleaseOvercount",
+                "TaskErrorUdiskRemove",
+                "TaskErrorHomeFailedX",
+                "TaskErrorHomeFailedZ",
+                "TaskErrorResinAbnormalHigh",
+                "TaskErrorResinAbnormalLow",
+                "TaskErrorHomeFailed",
+                "TaskErrorPlatFailed",
+                "TaskErrorError",
+                "TaskErrorMoveAbnormal",
+                "TaskErrorAicModelNone",
+                "TaskErrorAicModelWarp",
+                "TaskErrorHomeFailedY",
+                "TaskErrorFileError",
+                "TaskErrorCameraError",
+                "TaskErrorNetworkError",
+                "TaskErrorServerConnectFailed",
+                "TaskErrorDisconnectApp",
+                "TaskErrorCheckAutoResinFeeder",
+                "TaskErrorContainerResinLow",
+                "TaskErrorBottleDisconnect",
+                "TaskErrorFeedTimeout",
+                "TaskErrorTankTempSensorOffline",
+                "TaskErrorTankTempSensorError"
+            ]
+        },
+        "sdcp.TaskStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-comments": {
+                "TaskStatusCompleted": "Completed",
+                "TaskStatusExceptional": "Exceptional",
+                "TaskStatusOther": "Other",
+                "TaskStatusStopped": "Stopped"
+            },
+            "x-enum-varnames": [
+                "TaskStatusOther",
+                "TaskStatusCompleted",
+                "TaskStatusExceptional",
+                "TaskStatusStopped"
+            ]
+        },
+        "sdcp.TempSensorStatusOfUVLED": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "TempSensorStatusOfUVLEDDisconnected",
+                "TempSensorStatusOfUVLEDNormal",
+                "TempSensorStatusOfUVLEDAbnormal"
+            ]
+        },
+        "sdcp.TimeLapseStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "TimeLapseStatusOff",
+                "TimeLapseStatusOn"
+            ]
+        },
+        "sdcp.TimeLapseVideoStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-comments": {
+                "TimeLapseVideoStatusDeleted": "Deleted",
+                "TimeLapseVideoStatusGenerating": "Generating",
+                "TimeLapseVideoStatusGenerationFail": "Generation failed",
+                "TimeLapseVideoStatusNotShot": "Not shot",
+                "TimeLapseVideoStatusTimeLapseExist": "Time-lapse photography file exists"
+            },
+            "x-enum-varnames": [
+                "TimeLapseVideoStatusNotShot",
+                "TimeLapseVideoStatusTimeLapseExist",
+                "TimeLapseVideoStatusDeleted",
+                "TimeLapseVideoStatusGenerating",
+                "TimeLapseVideoStatusGenerationFail"
+            ]
+        },
+        "sdcp.UsbDiskStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "UsbDiskStatusDisconnected",
+                "UbsDiskStatusConnected"
+            ]
+        },
+        "sdcp.XMotorStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "XMotorStatusDisconnected",
+                "XMotorStatusConnected"
+            ]
+        },
+        "sdcp.ZMotorStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "ZMotorStatusDisconnected",
+                "ZMotorStatusConnected"
+            ]
+        },
+        "webhook.Event": {
+            "type": "string",
+            "enum": [
+                "print.started",
+                "print.paused",
+                "print.resumed",
+                "print.completed",
+                "print.stopped",
+                "print.failed",
+                "machine.online",
+                "machine.offline"
+            ],
+            "x-enum-comments": {
+                "EventPrintFailed": "Stopped by the machine because of a task error",
+                "EventPrintStopped": "Stopped by a user"
+            },
+            "x-enum-varnames": [
+                "EventPrintStarted",
+                "EventPrintPaused",
+                "EventPrintResumed",
+                "EventPrintCompleted",
+                "EventPrintStopped",
+                "EventPrintFailed",
+                "EventMachineOnline",
+                "EventMachineOffline"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Bearer token created with flux token create, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -188,7 +4495,7 @@ var SwaggerInfoapi = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
 	BasePath:         "/v1",
-	Schemes:          []string{"https"},
+	Schemes:          []string{"http", "https"},
 	Title:            "Flux API V1",
 	Description:      "API for Flux, V1",
 	InfoInstanceName: "api",
@@ -0,0 +1,164 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	heartbeatInterval = 15 * time.Second
+	writeTimeout      = 10 * time.Second
+)
+
+type Events struct {
+	logger types.Logger
+	app    *fiber.App
+
+	sdcp *sdcp.SDCP
+}
+
+func New(sdcp *sdcp.SDCP, logger types.Logger) *Events {
+	i := &Events{
+		logger: logger.SubLogger("events"),
+		app:    utils.DefaultFiberApp(),
+		sdcp:   sdcp,
+	}
+
+	i.init()
+
+	return i
+}
+
+func (a *Events) init() {
+	a.logger.Debug().Msg("initializing")
+	a.app.Get("/", a.Stream)
+	a.app.Use("/ws", func(ctx *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(ctx) {
+			return fiber.ErrUpgradeRequired
+		}
+		return ctx.Next()
+	})
+	a.app.Get("/ws", websocket.New(a.WebSocket))
+}
+
+// Stream godoc
+// @Description  Streams status, attributes, connection state, error, notice, print and discovery events for machines as Server-Sent Events
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        machines query string false "Comma separated list of machine IDs to stream events for, defaults to all machines"
+// @Success      200  {object} models.Event
+// @Router       /events [get]
+func (a *Events) Stream(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Stream request from %s", ctx.IP())
+
+	subscription := a.sdcp.Subscribe(sdcp.DefaultSubscriptionBuffer, machines(ctx.Query("machines"))...)
+	conn := ctx.Context().Conn()
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			// The server's write timeout applies to the whole response, so it is extended before every write
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			select {
+			case e, ok := <-subscription.C:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(models.NewEvent(e))
+				if err != nil {
+					a.logger.Error().Err(err).Msg("failed to encode event")
+					continue
+				}
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+				if err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				_, err := fmt.Fprint(w, ": heartbeat\n\n")
+				if err != nil {
+					return
+				}
+			}
+			if w.Flush() != nil {
+				a.logger.Debug().Msg("event stream closed")
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// WebSocket streams status, attributes, connection state, error and notice events for machines over a WebSocket.
+// The machines query parameter is a comma separated list of machine IDs to stream events for,
+// and defaults to all machines.
+func (a *Events) WebSocket(conn *websocket.Conn) {
+	a.logger.Debug().Msgf("received WebSocket request from %s", conn.IP())
+
+	subscription := a.sdcp.Subscribe(sdcp.DefaultSubscriptionBuffer, machines(conn.Query("machines"))...)
+	defer subscription.Close()
+
+	// Reading is required to process control frames and detect when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		select {
+		case <-closed:
+			return
+		case e, ok := <-subscription.C:
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if conn.WriteJSON(models.NewEvent(e)) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if conn.WriteMessage(websocket.PingMessage, nil) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (a *Events) App() *fiber.App {
+	return a.app
+}
+
+func machines(query string) []string {
+	var ids []string
+	for _, id := range strings.Split(query, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,273 @@
+package machine
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// UploadFile godoc
+// @Description  Uploads a file to the local storage of a machine
+// @Tags         machine
+// @Accept       multipart/form-data
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        file formData file true "File to upload"
+// @Success      200  {object} models.MachineFileTransferResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/files/{id} [post]
+func (a *Machine) UploadFile(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received UploadFile request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse file")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse file")
+	}
+
+	if header.Filename == "" || header.Size <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to open file")
+		return ctx.Status(fiber.StatusInternalServerError).SendString("failed to open file")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	transfer, err := m.UploadFile(ctx.UserContext(), f, header.Filename, header.Size)
+	if err != nil {
+		if errors.Is(err, sdcp.ErrUploadInProgress) {
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		}
+		return ctx.Status(errorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachineFileTransferResponse{
+		Transfer: fileTransfer(transfer),
+	})
+}
+
+// Transfer godoc
+// @Description  Retrieves the progress of the file upload in progress on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachineFileTransferResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /machine/files/{id}/transfer [get]
+func (a *Machine) Transfer(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Transfer request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	transfer, ok := m.Transfer()
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "no file upload in progress")
+	}
+
+	return ctx.JSON(&models.MachineFileTransferResponse{
+		Transfer: fileTransfer(transfer),
+	})
+}
+
+// CancelTransfer godoc
+// @Description  Cancels the file upload in progress on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {string} string
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /machine/files/{id}/transfer [delete]
+func (a *Machine) CancelTransfer(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received CancelTransfer request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	err := m.CancelUpload()
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).SendString("file upload cancelled")
+}
+
+// ListFiles godoc
+// @Description  Lists the files on the storage of a machine, descending into folders recursively
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        path query string false "Path to list, defaults to /local/"
+// @Param        recursive query bool false "Whether to descend into folders, defaults to true"
+// @Success      200  {object} models.MachineFilesResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/files/{id} [get]
+func (a *Machine) ListFiles(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received ListFiles request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	path := sdcp.Path(ctx.Query("path", string(sdcp.LocalPath(""))))
+	if !path.Valid() {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid path")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	var files []sdcp.FileList
+	if ctx.QueryBool("recursive", true) {
+		var err error
+		files, err = m.WalkFiles(ctx.UserContext(), path)
+		if err != nil {
+			return ctx.Status(errorStatus(err)).SendString(err.Error())
+		}
+	} else {
+		res, err := m.ListFiles(ctx.UserContext(), path)
+		if err != nil {
+			return ctx.Status(errorStatus(err)).SendString(err.Error())
+		}
+		files = res.FileList
+	}
+
+	res := &models.MachineFilesResponse{
+		Files:   make([]models.MachineFile, len(files)),
+		Storage: []models.MachineStorage{},
+	}
+	for i, f := range files {
+		res.Files[i] = models.MachineFile{
+			Name:        f.Name,
+			StorageType: f.StorageType,
+			Type:        f.Type,
+		}
+	}
+	for _, s := range sdcp.StorageUsage(files) {
+		res.Storage = append(res.Storage, models.MachineStorage{
+			StorageType: s.StorageType,
+			UsedSize:    s.UsedSize,
+			TotalSize:   s.TotalSize,
+		})
+	}
+
+	return ctx.JSON(res)
+}
+
+// DeleteFiles godoc
+// @Description  Deletes files and folders from the storage of a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        request  body models.MachineDeleteFilesRequest true  "Machine Delete Files Request"
+// @Success      200  {object} models.MachineDeleteFilesResponse
+// @Success      207  {object} models.MachineDeleteFilesResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/files/{id} [delete]
+func (a *Machine) DeleteFiles(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received DeleteFiles request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	body := new(models.MachineDeleteFilesRequest)
+	err := ctx.BodyParser(body)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse body")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	}
+
+	if len(body.Files) == 0 && len(body.Folders) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "no files or folders to delete")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	_, err = m.DeleteFiles(ctx.UserContext(), body.Files, body.Folders)
+	if err != nil {
+		var deleteErr *sdcp.DeleteFilesError
+		switch {
+		case errors.As(err, &deleteErr):
+			return ctx.Status(fiber.StatusMultiStatus).JSON(&models.MachineDeleteFilesResponse{
+				Failed: deleteErr.Failed,
+			})
+		case errors.Is(err, sdcp.ErrInvalidPath):
+			return ctx.Status(fiber.StatusBadRequest).SendString(err.Error())
+		default:
+			return ctx.Status(errorStatus(err)).SendString(err.Error())
+		}
+	}
+
+	return ctx.JSON(&models.MachineDeleteFilesResponse{
+		Failed: []sdcp.Path{},
+	})
+}
+
+func fileTransfer(t *sdcp.Transfer) models.MachineFileTransfer {
+	return models.MachineFileTransfer{
+		UUID:      t.UUID,
+		Filename:  t.Filename,
+		MD5:       t.MD5,
+		Size:      t.Size,
+		Sent:      t.Sent,
+		StartedAt: t.StartedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,246 @@
+package machine
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// maxBulkConcurrency limits how many machines a bulk action is applied to at once
+const maxBulkConcurrency = 16
+
+// bulkAction applies an action to a single machine, returning true if the machine was skipped
+type bulkAction func(ctx context.Context, m *sdcp.Machine) (bool, error)
+
+var bulkActions = map[string]bulkAction{
+	"refresh": func(ctx context.Context, m *sdcp.Machine) (bool, error) {
+		_, err := m.StatusRefreshWait(ctx)
+		if err != nil {
+			return false, err
+		}
+		_, err = m.AttributesRefreshWait(ctx)
+		return false, err
+	},
+	"pause": func(ctx context.Context, m *sdcp.Machine) (bool, error) {
+		if !m.Status().Printing() {
+			return true, nil
+		}
+		_, err := m.PausePrint(ctx)
+		return false, err
+	},
+	"resume": func(ctx context.Context, m *sdcp.Machine) (bool, error) {
+		if m.Status().PrintInfo.Status != sdcp.PrintInfoStatusPaused {
+			return true, nil
+		}
+		_, err := m.ResumePrint(ctx)
+		return false, err
+	},
+	"enable-timelapse":  setTimeLapse(true),
+	"disable-timelapse": setTimeLapse(false),
+}
+
+// setTimeLapse returns a bulkAction that enables or disables time-lapse, succeeding only once the machine
+// confirms the change
+func setTimeLapse(enabled bool) bulkAction {
+	status := sdcp.TimeLapseStatusOff
+	if enabled {
+		status = sdcp.TimeLapseStatusOn
+	}
+	return func(ctx context.Context, m *sdcp.Machine) (bool, error) {
+		if m.Status().TimeLapseStatus == status {
+			return true, nil
+		}
+		results := m.ApplySettings(ctx, sdcp.Settings{TimeLapse: &enabled})
+		return false, results[0].Err
+	}
+}
+
+// filter selects machines by their tags, model, connection state and machine status
+type filter struct {
+	ids    []string
+	tags   []string
+	model  string
+	state  sdcp.ConnectionState
+	status string
+}
+
+func (f *filter) match(m *sdcp.Machine) bool {
+	if len(f.ids) > 0 && !slices.Contains(f.ids, m.ID()) {
+		return false
+	}
+	tags := m.Tags()
+	for _, tag := range f.tags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	if f.model != "" && !strings.EqualFold(f.model, m.Attributes().MachineModel) {
+		return false
+	}
+	if f.state != "" && f.state != m.State() {
+		return false
+	}
+	if f.status != "" && !slices.ContainsFunc(m.Status().CurrentStatus, func(s sdcp.MachineStatus) bool {
+		return s.String() == f.status
+	}) {
+		return false
+	}
+	return true
+}
+
+func (a *Machine) machines(f *filter) []*sdcp.Machine {
+	return slices.DeleteFunc(a.sdcp.Machines(), func(m *sdcp.Machine) bool {
+		return !f.match(m)
+	})
+}
+
+// List godoc
+// @Description  Lists every registered machine along with a summary of its status
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        tag query string false "Comma separated tags every listed machine must have"
+// @Param        model query string false "Machine model"
+// @Param        state query string false "Connection state" Enums(connecting, online, offline)
+// @Param        status query string false "Machine status" Enums(idle, printing, file_transferring, exposure_testing, devices_testing)
+// @Success      200  {object} models.MachineListResponse
+// @Router       /machine [get]
+func (a *Machine) List(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received List request from %s", ctx.IP())
+
+	f := &filter{
+		model:  ctx.Query("model"),
+		state:  sdcp.ConnectionState(ctx.Query("state")),
+		status: ctx.Query("status"),
+	}
+	if tags := ctx.Query("tag"); tags != "" {
+		f.tags = strings.Split(tags, ",")
+	}
+
+	machines := a.machines(f)
+	res := &models.MachineListResponse{
+		Machines: make([]models.MachineSummary, len(machines)),
+	}
+	for i, m := range machines {
+		res.Machines[i] = summary(m)
+	}
+
+	return ctx.JSON(res)
+}
+
+// Bulk godoc
+// @Description  Applies an action to every selected machine concurrently. Machines that the action does not apply to,
+// @Description  such as machines that are not printing when pausing, are skipped. Time-lapse actions only succeed
+// @Description  once the machine confirms the change.
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        action path string true "Action" Enums(refresh, pause, resume, enable-timelapse, disable-timelapse)
+// @Param        request  body models.MachineBulkRequest false  "Machine Bulk Request"
+// @Success      200  {object} models.MachineBulkResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Router       /machine/bulk/{action} [post]
+func (a *Machine) Bulk(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Bulk request from %s", ctx.IP())
+
+	name := ctx.Params("action")
+	action, ok := bulkActions[name]
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "unknown action")
+	}
+
+	body := new(models.MachineBulkRequest)
+	if len(ctx.Body()) > 0 {
+		err := ctx.BodyParser(body)
+		if err != nil {
+			a.logger.Error().Err(err).Msg("failed to parse body")
+			return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+		}
+	}
+
+	machines := a.machines(&filter{
+		ids:    body.IDs,
+		tags:   body.Tags,
+		model:  body.Model,
+		state:  body.State,
+		status: body.Status,
+	})
+	res := &models.MachineBulkResponse{
+		Action:  name,
+		Results: make([]models.MachineBulkResult, len(machines)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxBulkConcurrency)
+	for i, m := range machines {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result := models.MachineBulkResult{ID: m.ID()}
+			skipped, err := action(ctx.UserContext(), m)
+			switch {
+			case err != nil:
+				result.Error = err.Error()
+			case skipped:
+				result.Skipped = true
+			default:
+				result.Success = true
+			}
+			res.Results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range res.Results {
+		switch {
+		case result.Success:
+			res.Succeeded++
+		case result.Skipped:
+			res.Skipped++
+		default:
+			res.Failed++
+		}
+	}
+	a.logger.Info().Int("succeeded", res.Succeeded).Int("failed", res.Failed).Int("skipped", res.Skipped).Msgf("applied bulk %s action", name)
+
+	return ctx.JSON(res)
+}
+
+func summary(m *sdcp.Machine) models.MachineSummary {
+	status := m.Status()
+	attributes := m.Attributes()
+	s := models.MachineSummary{
+		ID:              m.ID(),
+		Name:            attributes.MachineName,
+		Model:           attributes.MachineModel,
+		IP:              m.IP(),
+		Port:            m.Port(),
+		Label:           m.Label(),
+		Tags:            m.Tags(),
+		State:           m.State(),
+		Status:          make([]string, len(status.CurrentStatus)),
+		PrintStatus:     status.PrintInfo.Status.String(),
+		Filename:        status.PrintInfo.Filename,
+		CurrentLayer:    status.PrintInfo.CurrentLayer,
+		TotalLayer:      status.PrintInfo.TotalLayer,
+		TimeLapseStatus: status.TimeLapseStatus,
+	}
+	for i, machineStatus := range status.CurrentStatus {
+		s.Status[i] = machineStatus.String()
+	}
+	if s.TotalLayer > 0 {
+		s.Progress = float64(s.CurrentLayer) / float64(s.TotalLayer) * 100
+	}
+	return s
+}
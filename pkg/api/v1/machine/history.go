@@ -0,0 +1,133 @@
+package machine
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maximumHistoryLimit = 100
+)
+
+// History godoc
+// @Description  Retrieves a page of the print history of a machine, most recent first
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        offset query int false "Number of tasks to skip"
+// @Param        limit query int false "Maximum number of tasks to return"
+// @Success      200  {object} models.MachineHistoryResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/history/{id} [get]
+func (a *Machine) History(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received History request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	offset := ctx.QueryInt("offset", 0)
+	if offset < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid offset")
+	}
+
+	limit := ctx.QueryInt("limit", defaultHistoryLimit)
+	if limit <= 0 || limit > maximumHistoryLimit {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid limit")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	ids, err := m.History(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(errorStatus(err)).SendString(err.Error())
+	}
+
+	res := &models.MachineHistoryResponse{
+		Total:  len(ids),
+		Offset: offset,
+		Limit:  limit,
+		Tasks:  []models.MachineTask{},
+	}
+	if offset >= len(ids) {
+		return ctx.JSON(res)
+	}
+
+	details, err := m.TaskDetails(ctx.UserContext(), ids[offset:min(offset+limit, len(ids))]...)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).SendString(err.Error())
+	}
+
+	for _, d := range details {
+		res.Tasks = append(res.Tasks, machineTask(d))
+	}
+
+	return ctx.JSON(res)
+}
+
+// Task godoc
+// @Description  Retrieves the details of a single task from the print history of a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        task path string true "task"
+// @Success      200  {object} models.MachineTaskResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/history/{id}/{task} [get]
+func (a *Machine) Task(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Task request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	task := ctx.Params("task")
+	if task == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid task")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	details, err := m.TaskDetails(ctx.UserContext(), task)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).SendString(err.Error())
+	}
+
+	if len(details) == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "task not found")
+	}
+
+	return ctx.JSON(&models.MachineTaskResponse{
+		Task: machineTask(details[0]),
+	})
+}
+
+func machineTask(d sdcp.TaskDetails) models.MachineTask {
+	t := models.MachineTask{
+		Task:   d,
+		Status: d.TaskStatus.String(),
+	}
+	if d.ErrorStatusReason != sdcp.TaskErrorOk {
+		t.Error = d.ErrorStatusReason.String()
+	}
+	return t
+}
@@ -0,0 +1,42 @@
+package machine
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+)
+
+// EventLog godoc
+// @Description  Retrieves the most recent errors and notices reported by a machine, oldest first
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachineEventLogResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /machine/log/{id} [get]
+func (a *Machine) EventLog(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received EventLog request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	log := m.EventLog()
+	res := &models.MachineEventLogResponse{
+		Events: make([]*models.Event, len(log)),
+	}
+	for i, e := range log {
+		res.Events[i] = models.NewEvent(e)
+	}
+
+	return ctx.JSON(res)
+}
@@ -20,7 +20,7 @@ type Machine struct {
 func New(sdcp *sdcp.SDCP, logger types.Logger) *Machine {
 	i := &Machine{
 		logger: logger.SubLogger("machine"),
-		app:    utils.DefaultFiberApp(),
+		app:    utils.DefaultFiberApp(1024 * 1024 * 500),
 		sdcp:   sdcp,
 	}
 
@@ -31,6 +31,9 @@ func New(sdcp *sdcp.SDCP, logger types.Logger) *Machine {
 
 func (a *Machine) init() {
 	a.logger.Debug().Msg("initializing")
+	a.app.Get("/", a.List)
+	a.app.Post("/bulk/:action", a.Bulk)
+
 	a.app.Post("/register", a.Register)
 	a.app.Post("/unregister/:id", a.Unregister)
 
@@ -42,6 +45,28 @@ func (a *Machine) init() {
 
 	a.app.Post("/video/:id", a.EnableVideo)
 	a.app.Delete("/video/:id", a.DisableVideo)
+
+	a.app.Patch("/settings/:id", a.Settings)
+
+	a.app.Post("/print/:id", a.StartPrint)
+	a.app.Delete("/print/:id", a.StopPrint)
+	a.app.Post("/print/:id/pause", a.PausePrint)
+	a.app.Post("/print/:id/resume", a.ResumePrint)
+	a.app.Post("/print/:id/stop-feeding", a.StopFeedingMaterial)
+	a.app.Post("/print/:id/skip-preheat", a.SkipPreheating)
+
+	a.app.Post("/preflight/:id", a.Preflight)
+
+	a.app.Get("/files/:id", a.ListFiles)
+	a.app.Post("/files/:id", a.UploadFile)
+	a.app.Delete("/files/:id", a.DeleteFiles)
+	a.app.Get("/files/:id/transfer", a.Transfer)
+	a.app.Delete("/files/:id/transfer", a.CancelTransfer)
+
+	a.app.Get("/history/:id", a.History)
+	a.app.Get("/history/:id/:task", a.Task)
+
+	a.app.Get("/log/:id", a.EventLog)
 }
 
 // Register godoc
@@ -64,7 +89,17 @@ func (a *Machine) Register(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
 	}
 
-	err = a.sdcp.Register(body.MachineID, body.MachineIP)
+	if body.MachineID == "" || body.MachineIP == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid machine id or ip")
+	}
+
+	err = a.sdcp.Register(sdcp.Registration{
+		ID:    body.MachineID,
+		IP:    body.MachineIP,
+		Port:  body.MachinePort,
+		Label: body.Label,
+		Tags:  body.Tags,
+	})
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
@@ -76,6 +111,7 @@ func (a *Machine) Register(ctx *fiber.Ctx) error {
 
 	status := machine.Status()
 	return ctx.JSON(&models.MachineStatusResponse{
+		State:  machine.State(),
 		Status: *status,
 	})
 }
@@ -132,6 +168,7 @@ func (a *Machine) Status(ctx *fiber.Ctx) error {
 
 	status := m.Status()
 	return ctx.JSON(&models.MachineStatusResponse{
+		State:  m.State(),
 		Status: *status,
 	})
 }
@@ -146,6 +183,7 @@ func (a *Machine) Status(ctx *fiber.Ctx) error {
 // @Failure      400  {string} string
 // @Failure      404  {string} string
 // @Failure      500  {string} string
+// @Failure      504  {string} string
 // @Router       /machine/status/{id} [post]
 func (a *Machine) RefreshStatus(ctx *fiber.Ctx) error {
 	a.logger.Debug().Msgf("received RefreshStatus request from %s", ctx.IP())
@@ -160,12 +198,13 @@ func (a *Machine) RefreshStatus(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "machine not found")
 	}
 
-	status, err := m.StatusRefreshWait(ctx.Context())
+	status, err := m.StatusRefreshWait(ctx.UserContext())
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		return ctx.Status(errorStatus(err)).SendString(err.Error())
 	}
 
 	return ctx.JSON(&models.MachineStatusResponse{
+		State:  m.State(),
 		Status: *status,
 	})
 }
@@ -210,6 +249,7 @@ func (a *Machine) Attributes(ctx *fiber.Ctx) error {
 // @Failure      400  {string} string
 // @Failure      404  {string} string
 // @Failure      500  {string} string
+// @Failure      504  {string} string
 // @Router       /machine/attributes/{id} [post]
 func (a *Machine) RefreshAttributes(ctx *fiber.Ctx) error {
 	a.logger.Debug().Msgf("received RefreshAttributes request from %s", ctx.IP())
@@ -224,9 +264,9 @@ func (a *Machine) RefreshAttributes(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "machine not found")
 	}
 
-	attributes, err := m.AttributesRefreshWait(ctx.Context())
+	attributes, err := m.AttributesRefreshWait(ctx.UserContext())
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		return ctx.Status(errorStatus(err)).SendString(err.Error())
 	}
 
 	return ctx.JSON(&models.MachineAttributesResponse{
@@ -243,7 +283,9 @@ func (a *Machine) RefreshAttributes(ctx *fiber.Ctx) error {
 // @Success      200  {object} models.MachineVideoResponse
 // @Failure      400  {string} string
 // @Failure      404  {string} string
+// @Failure      409  {string} string
 // @Failure      500  {string} string
+// @Failure      504  {string} string
 // @Router       /machine/video/{id} [post]
 func (a *Machine) EnableVideo(ctx *fiber.Ctx) error {
 	a.logger.Debug().Msgf("received EnableVideo request from %s", ctx.IP())
@@ -258,9 +300,9 @@ func (a *Machine) EnableVideo(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "machine not found")
 	}
 
-	status, err := m.EnableDisableVideo(ctx.Context(), true)
+	status, err := m.EnableDisableVideo(ctx.UserContext(), true)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		return ctx.Status(videoErrorStatus(err)).SendString(err.Error())
 	}
 
 	return ctx.JSON(&models.MachineVideoResponse{
@@ -278,6 +320,7 @@ func (a *Machine) EnableVideo(ctx *fiber.Ctx) error {
 // @Failure      400  {string} string
 // @Failure      404  {string} string
 // @Failure      500  {string} string
+// @Failure      504  {string} string
 // @Router       /machine/video/{id} [delete]
 func (a *Machine) DisableVideo(ctx *fiber.Ctx) error {
 	a.logger.Debug().Msgf("received DisableVideo request from %s", ctx.IP())
@@ -292,9 +335,9 @@ func (a *Machine) DisableVideo(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "machine not found")
 	}
 
-	status, err := m.EnableDisableVideo(ctx.Context(), false)
+	status, err := m.EnableDisableVideo(ctx.UserContext(), false)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		return ctx.Status(videoErrorStatus(err)).SendString(err.Error())
 	}
 
 	return ctx.JSON(&models.MachineVideoResponse{
@@ -0,0 +1,105 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/sdcp/sdcptest"
+)
+
+func TestMachine(t *testing.T) {
+	p, err := sdcptest.NewPrinter(sdcptest.Config{})
+	require.NoError(t, err)
+	t.Cleanup(p.Close)
+	p.AddFile(sdcp.LocalPath("model.ctb"), 1024, 100)
+
+	logger := logging.Test(t, logging.Slog, t.Name())
+	s := sdcp.New(logger, nil)
+	t.Cleanup(s.Close)
+
+	// The app is served by a real server, as requests to machines can outlast the timeout of app.Test
+	app := New(s, logger).App()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = app.Listener(listener)
+	}()
+	t.Cleanup(func() {
+		_ = app.Shutdown()
+	})
+
+	send := func(method string, target string, body any, v any) int {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, "http://"+listener.Addr().String()+target, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		if v != nil && res.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+		}
+		return res.StatusCode
+	}
+
+	registration := p.Registration()
+	status := new(models.MachineStatusResponse)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPost, "/register", &models.MachineRegisterRequest{
+		MachineID:   registration.ID,
+		MachineIP:   registration.IP,
+		MachinePort: registration.Port,
+		Tags:        []string{"resin", "lab"},
+	}, status))
+	require.Equal(t, sdcp.ConnectionStateOnline, status.State)
+
+	attributes := new(models.MachineAttributesResponse)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/attributes/"+p.ID(), nil, attributes))
+	require.Equal(t, sdcptest.DefaultModel, attributes.Attributes.MachineModel)
+
+	require.Equal(t, fiber.StatusNotFound, send(fiber.MethodPost, "/print/"+p.ID(), &models.MachineStartPrintRequest{Filename: "missing.ctb"}, nil))
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPost, "/print/"+p.ID(), &models.MachineStartPrintRequest{Filename: "model.ctb"}, nil))
+	require.Equal(t, fiber.StatusConflict, send(fiber.MethodPost, "/print/"+p.ID(), &models.MachineStartPrintRequest{Filename: "model.ctb"}, nil))
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodDelete, "/print/"+p.ID(), nil, nil))
+
+	list := new(models.MachineListResponse)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/?tag=resin,lab&state=online", nil, list))
+	require.Len(t, list.Machines, 1)
+	require.Equal(t, p.ID(), list.Machines[0].ID)
+	require.Equal(t, sdcptest.DefaultModel, list.Machines[0].Model)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/?tag=resin,other", nil, list))
+	require.Empty(t, list.Machines)
+
+	bulk := new(models.MachineBulkResponse)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPost, "/bulk/enable-timelapse", &models.MachineBulkRequest{Tags: []string{"lab"}}, bulk))
+	require.Equal(t, []models.MachineBulkResult{{ID: p.ID(), Success: true}}, bulk.Results)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/", nil, list))
+	require.Equal(t, sdcp.TimeLapseStatusOn, list.Machines[0].TimeLapseStatus)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPost, "/bulk/pause", nil, bulk))
+	require.Equal(t, 1, bulk.Skipped)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPost, "/bulk/refresh", &models.MachineBulkRequest{IDs: []string{"unknown"}}, bulk))
+	require.Empty(t, bulk.Results)
+	require.Equal(t, fiber.StatusNotFound, send(fiber.MethodPost, "/bulk/unknown", nil, nil))
+
+	name, timeLapse := "Renamed Printer", false
+	settings := new(models.MachineSettingsResponse)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPatch, "/settings/"+p.ID(), &models.MachineSettingsRequest{Name: &name, TimeLapse: &timeLapse}, settings))
+	require.Equal(t, []string{sdcp.SettingName, sdcp.SettingTimeLapse}, settings.Applied)
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodGet, "/attributes/"+p.ID(), nil, attributes))
+	require.Equal(t, name, attributes.Attributes.MachineName)
+	require.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPatch, "/settings/"+p.ID(), &models.MachineSettingsRequest{}, nil))
+
+	require.Equal(t, fiber.StatusNotFound, send(fiber.MethodGet, "/status/unknown", nil, nil))
+	require.Equal(t, fiber.StatusOK, send(fiber.MethodPost, "/unregister/"+p.ID(), nil, nil))
+}
@@ -0,0 +1,81 @@
+package machine
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/preflight"
+)
+
+// Preflight godoc
+// @Description  Checks whether a sliced file can be uploaded to and printed by a machine, without uploading it
+// @Tags         machine
+// @Accept       multipart/form-data
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        file formData file true "File to check"
+// @Success      200  {object} models.MachinePreflightResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /machine/preflight/{id} [post]
+func (a *Machine) Preflight(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Preflight request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse file")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse file")
+	}
+
+	if header.Filename == "" || header.Size <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to open file")
+		return ctx.Status(fiber.StatusInternalServerError).SendString("failed to open file")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	file := preflight.NewFile(header.Filename, f, header.Size)
+	report := preflight.CheckMachine(m, file)
+
+	res := &models.MachinePreflightResponse{
+		Passed: report.Passed(),
+		File: models.MachinePreflightFile{
+			Filename: file.Name,
+			Size:     file.Size,
+		},
+		Problems: report.Problems,
+	}
+	if res.Problems == nil {
+		res.Problems = []preflight.Problem{}
+	}
+	if metadata := file.Metadata; metadata != nil {
+		res.File.FileType = metadata.FileType
+		res.File.MachineName = metadata.MachineName
+		res.File.Resolution = metadata.Resolution()
+		res.File.LayerCount = metadata.LayerCount
+		res.File.LayerHeight = metadata.LayerHeight
+		res.File.Height = metadata.Height
+		res.File.ExposureTime = metadata.ExposureTime.Seconds()
+		res.File.PrintTime = int64(metadata.PrintTime.Seconds())
+		res.File.Volume = metadata.Volume
+	}
+
+	return ctx.JSON(res)
+}
@@ -0,0 +1,286 @@
+package machine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// StartPrint godoc
+// @Description  Starts printing a file on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        request  body models.MachineStartPrintRequest true  "Machine Start Print Request"
+// @Success      200  {object} models.MachinePrintResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      422  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/print/{id} [post]
+func (a *Machine) StartPrint(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received StartPrint request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	body := new(models.MachineStartPrintRequest)
+	err := ctx.BodyParser(body)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse body")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	}
+
+	if body.Filename == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid filename")
+	}
+
+	if body.StartLayer < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid start layer")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	res, err := m.StartPrint(ctx.UserContext(), body.Filename, body.StartLayer)
+	if err != nil {
+		return ctx.Status(controlErrorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachinePrintResponse{
+		Ack: res.Ack,
+	})
+}
+
+// StopPrint godoc
+// @Description  Stops the current print on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachinePrintResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/print/{id} [delete]
+func (a *Machine) StopPrint(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received StopPrint request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	res, err := m.StopPrint(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(controlErrorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachinePrintResponse{
+		Ack: res.Ack,
+	})
+}
+
+// PausePrint godoc
+// @Description  Pauses the current print on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachinePrintResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/print/{id}/pause [post]
+func (a *Machine) PausePrint(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received PausePrint request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	res, err := m.PausePrint(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(controlErrorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachinePrintResponse{
+		Ack: res.Ack,
+	})
+}
+
+// ResumePrint godoc
+// @Description  Resumes the current paused print on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachinePrintResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/print/{id}/resume [post]
+func (a *Machine) ResumePrint(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received ResumePrint request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	res, err := m.ResumePrint(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(controlErrorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachinePrintResponse{
+		Ack: res.Ack,
+	})
+}
+
+// StopFeedingMaterial godoc
+// @Description  Stops the automatic material feeding on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachinePrintResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/print/{id}/stop-feeding [post]
+func (a *Machine) StopFeedingMaterial(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received StopFeedingMaterial request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	res, err := m.StopFeedingMaterial(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(controlErrorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachinePrintResponse{
+		Ack: res.Ack,
+	})
+}
+
+// SkipPreheating godoc
+// @Description  Skips the preheating phase of the current print on a machine
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MachinePrintResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Failure      504  {string} string
+// @Router       /machine/print/{id}/skip-preheat [post]
+func (a *Machine) SkipPreheating(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received SkipPreheating request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	res, err := m.SkipPreheating(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(controlErrorStatus(err)).SendString(err.Error())
+	}
+
+	return ctx.JSON(&models.MachinePrintResponse{
+		Ack: res.Ack,
+	})
+}
+
+// controlErrorStatus maps errors returned by print control commands to HTTP status codes
+func controlErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sdcp.ErrControlBusy):
+		return fiber.StatusConflict
+	case errors.Is(err, sdcp.ErrControlFileNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, sdcp.ErrControlMD5Failed),
+		errors.Is(err, sdcp.ErrControlFileIOFailed),
+		errors.Is(err, sdcp.ErrControlInvalidResolution),
+		errors.Is(err, sdcp.ErrControlUnknownFormat),
+		errors.Is(err, sdcp.ErrControlUnknownModel):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return errorStatus(err)
+	}
+}
+
+// videoErrorStatus maps errors returned by video stream requests to HTTP status codes
+func videoErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sdcp.ErrStreamLimit):
+		return fiber.StatusConflict
+	case errors.Is(err, sdcp.ErrStreamCameraNotFound):
+		return fiber.StatusNotFound
+	default:
+		return errorStatus(err)
+	}
+}
+
+// errorStatus maps errors returned by requests to machines to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, sdcp.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return fiber.StatusGatewayTimeout
+	case errors.Is(err, sdcp.ErrNotConnected), errors.Is(err, sdcp.ErrDisconnected), errors.Is(err, sdcp.ErrQueueFull):
+		return fiber.StatusServiceUnavailable
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
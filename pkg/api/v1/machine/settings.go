@@ -0,0 +1,83 @@
+package machine
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// Settings godoc
+// @Description  Applies several settings to a machine, waiting for the machine to confirm each one.
+// @Description  Reports which settings took effect, failing only if none of them did.
+// @Tags         machine
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        request  body models.MachineSettingsRequest true  "Machine Settings Request"
+// @Success      200  {object} models.MachineSettingsResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {object} models.MachineSettingsResponse
+// @Failure      503  {object} models.MachineSettingsResponse
+// @Failure      504  {object} models.MachineSettingsResponse
+// @Router       /machine/settings/{id} [patch]
+func (a *Machine) Settings(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Settings request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	body := new(models.MachineSettingsRequest)
+	err := ctx.BodyParser(body)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse body")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	}
+
+	if body.Name == nil && body.TimeLapse == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "no settings")
+	}
+
+	if body.Name != nil && *body.Name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid name")
+	}
+
+	m, ok := a.sdcp.GetMachine(id)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	}
+
+	results := m.ApplySettings(ctx.UserContext(), sdcp.Settings{
+		Name:      body.Name,
+		TimeLapse: body.TimeLapse,
+	})
+	res := &models.MachineSettingsResponse{
+		Results: make([]models.MachineSettingResult, 0, len(results)),
+		Applied: make([]string, 0, len(results)),
+	}
+	var failed error
+	for _, r := range results {
+		result := models.MachineSettingResult{
+			Setting: r.Setting,
+			Applied: r.Applied,
+		}
+		if r.Err != nil {
+			result.Error = r.Err.Error()
+			if failed == nil {
+				failed = r.Err
+			}
+		}
+		if r.Applied {
+			res.Applied = append(res.Applied, r.Setting)
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	if len(res.Applied) == 0 && failed != nil {
+		return ctx.Status(errorStatus(failed)).JSON(res)
+	}
+	return ctx.JSON(res)
+}
@@ -0,0 +1,149 @@
+package maintenance
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/maintenance"
+)
+
+type Maintenance struct {
+	logger types.Logger
+	app    *fiber.App
+
+	tracker *maintenance.Tracker
+}
+
+func New(tracker *maintenance.Tracker, logger types.Logger) *Maintenance {
+	i := &Maintenance{
+		logger:  logger.SubLogger("maintenance"),
+		app:     utils.DefaultFiberApp(),
+		tracker: tracker,
+	}
+
+	i.init()
+
+	return i
+}
+
+func (a *Maintenance) init() {
+	a.logger.Debug().Msg("initializing")
+	a.app.Get("/", a.Alerts)
+	a.app.Get("/:id", a.Wear)
+	a.app.Post("/:id/replace", a.Replace)
+}
+
+// Alerts godoc
+// @Description  Retrieves the most recent maintenance alerts of every machine, oldest first
+// @Tags         maintenance
+// @Accept       application/json
+// @Produce      application/json
+// @Success      200  {object} models.MaintenanceAlertsResponse
+// @Router       /maintenance [get]
+func (a *Maintenance) Alerts(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Alerts request from %s", ctx.IP())
+
+	return ctx.JSON(&models.MaintenanceAlertsResponse{
+		Alerts: alerts(a.tracker.Alerts()),
+	})
+}
+
+// Wear godoc
+// @Description  Retrieves the wear and forecast remaining life of the consumables of a machine, along with its maintenance alerts
+// @Tags         maintenance
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.MaintenanceResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /maintenance/{id} [get]
+func (a *Maintenance) Wear(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Wear request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	wear, err := a.tracker.Wear(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	res := &models.MaintenanceResponse{
+		Wear:   make([]models.MaintenanceWear, len(wear)),
+		Alerts: alerts(a.tracker.Alerts(id)),
+	}
+	for i, w := range wear {
+		res.Wear[i] = models.NewMaintenanceWear(w)
+	}
+
+	return ctx.JSON(res)
+}
+
+// Replace godoc
+// @Description  Records that a consumable of a machine was replaced, resetting its wear
+// @Tags         maintenance
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        request  body models.MaintenanceReplaceRequest true  "Maintenance Replace Request"
+// @Success      200  {object} models.MaintenanceResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /maintenance/{id}/replace [post]
+func (a *Maintenance) Replace(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Replace request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	body := new(models.MaintenanceReplaceRequest)
+	err := ctx.BodyParser(body)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse body")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	}
+
+	err = a.tracker.Replace(id, body.Consumable)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return a.Wear(ctx)
+}
+
+func (a *Maintenance) App() *fiber.App {
+	return a.app
+}
+
+func errorStatus(err error) error {
+	switch {
+	case errors.Is(err, maintenance.ErrUnknownConsumable):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, maintenance.ErrUnknownMachine):
+		return fiber.NewError(fiber.StatusNotFound, "machine not found")
+	case errors.Is(err, maintenance.ErrNoUsage):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func alerts(alerts []maintenance.Alert) []models.MaintenanceAlert {
+	res := make([]models.MaintenanceAlert, len(alerts))
+	for i, alert := range alerts {
+		res[i] = models.NewMaintenanceAlert(alert)
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package models
+
+import "github.com/shivanshvij/flux/pkg/sdcp"
+
+type EventPrint struct {
+	Type         sdcp.PrintEventType `json:"type"`
+	TaskID       string              `json:"task_id"`
+	Filename     string              `json:"filename"`
+	CurrentLayer int                 `json:"current_layer"`
+	TotalLayer   int                 `json:"total_layer"`
+	Error        sdcp.PrintInfoError `json:"error"`
+}
+
+type EventDiscovery struct {
+	Type         sdcp.DiscoveryEventType `json:"type"`
+	IP           string                  `json:"ip"`
+	PreviousIP   string                  `json:"previous_ip,omitempty"`
+	MachineName  string                  `json:"machine_name"`
+	MachineModel string                  `json:"machine_model"`
+}
+
+type Event struct {
+	Type       sdcp.EventType         `json:"type"`
+	MachineID  string                 `json:"machine_id"`
+	Time       int64                  `json:"time"`
+	Status     *sdcp.Status           `json:"status,omitempty"`
+	Attributes *sdcp.Attributes       `json:"attributes,omitempty"`
+	State      sdcp.ConnectionState   `json:"state,omitempty"`
+	Error      *sdcp.ErrorData        `json:"error,omitempty"`
+	Notice     *sdcp.NotificationData `json:"notice,omitempty"`
+	Print      *EventPrint            `json:"print,omitempty"`
+	Discovery  *EventDiscovery        `json:"discovery,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+}
+
+func NewEvent(e sdcp.Event) *Event {
+	event := &Event{
+		Type:       e.Type,
+		MachineID:  e.MachineID,
+		Time:       e.Time.UnixMilli(),
+		Status:     e.Status,
+		Attributes: e.Attributes,
+		State:      e.State,
+		Error:      e.Error,
+		Notice:     e.Notice,
+	}
+	if e.Print != nil {
+		event.Print = &EventPrint{
+			Type:         e.Print.Type,
+			TaskID:       e.Print.TaskID,
+			Filename:     e.Print.Filename,
+			CurrentLayer: e.Print.CurrentLayer,
+			TotalLayer:   e.Print.TotalLayer,
+			Error:        e.Print.Error,
+		}
+	}
+	if e.Discovery != nil {
+		event.Discovery = &EventDiscovery{
+			Type:         e.Discovery.Type,
+			IP:           e.Discovery.IP,
+			PreviousIP:   e.Discovery.PreviousIP,
+			MachineName:  e.Discovery.MachineName,
+			MachineModel: e.Discovery.MachineModel,
+		}
+	}
+	switch {
+	case e.Error != nil:
+		event.Message = e.Error.Data.ErrorCode.String()
+	case e.Notice != nil:
+		event.Message = e.Notice.Data.Type.String()
+	}
+	return event
+}
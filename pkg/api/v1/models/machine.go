@@ -1,14 +1,21 @@
 package models
 
-import "github.com/shivanshvij/flux/pkg/sdcp"
+import (
+	"github.com/shivanshvij/flux/pkg/preflight"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
 
 type MachineRegisterRequest struct {
-	MachineID string `json:"machine_id"`
-	MachineIP string `json:"machine_ip"`
+	MachineID   string   `json:"machine_id"`
+	MachineIP   string   `json:"machine_ip"`
+	MachinePort int      `json:"machine_port,omitempty"`
+	Label       string   `json:"label"`
+	Tags        []string `json:"tags"`
 }
 
 type MachineStatusResponse struct {
-	Status sdcp.Status `json:"status"`
+	State  sdcp.ConnectionState `json:"state"`
+	Status sdcp.Status          `json:"status"`
 }
 
 type MachineAttributesResponse struct {
@@ -18,3 +25,154 @@ type MachineAttributesResponse struct {
 type MachineVideoResponse struct {
 	Status sdcp.EnableDisableVideoStreamResponse `json:"status"`
 }
+
+type MachineStartPrintRequest struct {
+	Filename   string `json:"filename"`
+	StartLayer int    `json:"start_layer"`
+}
+
+type MachinePrintResponse struct {
+	Ack sdcp.ControlAck `json:"ack"`
+}
+
+type MachineFileTransfer struct {
+	UUID      string `json:"uuid"`
+	Filename  string `json:"filename"`
+	MD5       string `json:"md5"`
+	Size      int64  `json:"size"`
+	Sent      int64  `json:"sent"`
+	StartedAt int64  `json:"started_at"` // Unix time in milliseconds
+}
+
+type MachineFileTransferResponse struct {
+	Transfer MachineFileTransfer `json:"transfer"`
+}
+
+type MachineFile struct {
+	Name        sdcp.Path        `json:"name"`
+	StorageType sdcp.StorageType `json:"storage_type"`
+	Type        sdcp.FileType    `json:"type"`
+}
+
+type MachineStorage struct {
+	StorageType sdcp.StorageType `json:"storage_type"`
+	UsedSize    int              `json:"used_size"`
+	TotalSize   int              `json:"total_size"`
+}
+
+type MachineFilesResponse struct {
+	Files   []MachineFile    `json:"files"`
+	Storage []MachineStorage `json:"storage"`
+}
+
+type MachineDeleteFilesRequest struct {
+	Files   []sdcp.Path `json:"files"`
+	Folders []sdcp.Path `json:"folders"`
+}
+
+type MachineDeleteFilesResponse struct {
+	Failed []sdcp.Path `json:"failed"`
+}
+
+type MachineTask struct {
+	Task   sdcp.TaskDetails `json:"task"`
+	Status string           `json:"status"`
+	Error  string           `json:"error"`
+}
+
+type MachineHistoryResponse struct {
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+	Tasks  []MachineTask `json:"tasks"`
+}
+
+type MachineTaskResponse struct {
+	Task MachineTask `json:"task"`
+}
+
+type MachineEventLogResponse struct {
+	Events []*Event `json:"events"`
+}
+
+type MachinePreflightFile struct {
+	Filename     string                 `json:"filename"`
+	Size         int64                  `json:"size"`
+	FileType     sdcp.SupportedFileType `json:"file_type"`
+	MachineName  string                 `json:"machine_name,omitempty"`
+	Resolution   string                 `json:"resolution,omitempty"`
+	LayerCount   int                    `json:"layer_count,omitempty"`
+	LayerHeight  float64                `json:"layer_height,omitempty"`
+	Height       float64                `json:"height,omitempty"`
+	ExposureTime float64                `json:"exposure_time,omitempty"`
+	PrintTime    int64                  `json:"print_time,omitempty"`
+	Volume       float64                `json:"volume,omitempty"`
+}
+
+type MachinePreflightResponse struct {
+	Passed   bool                 `json:"passed"`
+	File     MachinePreflightFile `json:"file"`
+	Problems []preflight.Problem  `json:"problems"`
+}
+
+type MachineSummary struct {
+	ID              string               `json:"id"`
+	Name            string               `json:"name"`
+	Model           string               `json:"model"`
+	IP              string               `json:"ip"`
+	Port            int                  `json:"port"`
+	Label           string               `json:"label"`
+	Tags            []string             `json:"tags"`
+	State           sdcp.ConnectionState `json:"state"`
+	Status          []string             `json:"status"`
+	PrintStatus     string               `json:"print_status"`
+	Filename        string               `json:"filename,omitempty"`
+	CurrentLayer    int                  `json:"current_layer"`
+	TotalLayer      int                  `json:"total_layer"`
+	Progress        float64              `json:"progress"`
+	TimeLapseStatus sdcp.TimeLapseStatus `json:"time_lapse_status"`
+}
+
+type MachineListResponse struct {
+	Machines []MachineSummary `json:"machines"`
+}
+
+// MachineBulkRequest selects the machines a bulk action is applied to, which is every machine if it is empty
+type MachineBulkRequest struct {
+	IDs    []string             `json:"ids"`
+	Tags   []string             `json:"tags"`
+	Model  string               `json:"model"`
+	State  sdcp.ConnectionState `json:"state"`
+	Status string               `json:"status"`
+}
+
+type MachineBulkResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type MachineBulkResponse struct {
+	Action    string              `json:"action"`
+	Results   []MachineBulkResult `json:"results"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Skipped   int                 `json:"skipped"`
+}
+
+type MachineSettingsRequest struct {
+	Name      *string `json:"name,omitempty"`
+	TimeLapse *bool   `json:"time_lapse,omitempty"`
+}
+
+type MachineSettingResult struct {
+	Setting string `json:"setting"`
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+type MachineSettingsResponse struct {
+	Results []MachineSettingResult `json:"results"`
+	Applied []string               `json:"applied"`
+}
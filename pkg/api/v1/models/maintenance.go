@@ -0,0 +1,63 @@
+package models
+
+import "github.com/shivanshvij/flux/pkg/maintenance"
+
+type MaintenanceWear struct {
+	Consumable  maintenance.Consumable `json:"consumable"`
+	Used        float64                `json:"used"`
+	Limit       float64                `json:"limit"`
+	Remaining   float64                `json:"remaining"`
+	Fraction    float64                `json:"fraction"`
+	RatePerDay  float64                `json:"rate_per_day"`
+	ExhaustedAt int64                  `json:"exhausted_at,omitempty"`
+	ReplacedAt  int64                  `json:"replaced_at,omitempty"`
+	Level       maintenance.Level      `json:"level"`
+}
+
+func NewMaintenanceWear(w maintenance.Wear) MaintenanceWear {
+	wear := MaintenanceWear{
+		Consumable: w.Consumable,
+		Used:       w.Used,
+		Limit:      w.Limit,
+		Remaining:  w.Remaining,
+		Fraction:   w.Fraction,
+		RatePerDay: w.Rate,
+		Level:      w.Level,
+	}
+	if !w.Exhausted.IsZero() {
+		wear.ExhaustedAt = w.Exhausted.UnixMilli()
+	}
+	if !w.ReplacedAt.IsZero() {
+		wear.ReplacedAt = w.ReplacedAt.UnixMilli()
+	}
+	return wear
+}
+
+type MaintenanceAlert struct {
+	MachineID string          `json:"machine_id"`
+	Time      int64           `json:"time"`
+	Wear      MaintenanceWear `json:"wear"`
+	Message   string          `json:"message"`
+}
+
+func NewMaintenanceAlert(a maintenance.Alert) MaintenanceAlert {
+	return MaintenanceAlert{
+		MachineID: a.MachineID,
+		Time:      a.Time.UnixMilli(),
+		Wear:      NewMaintenanceWear(a.Wear),
+		Message:   a.String(),
+	}
+}
+
+type MaintenanceResponse struct {
+	Wear   []MaintenanceWear  `json:"wear"`
+	Alerts []MaintenanceAlert `json:"alerts"`
+}
+
+type MaintenanceAlertsResponse struct {
+	Alerts []MaintenanceAlert `json:"alerts"`
+}
+
+type MaintenanceReplaceRequest struct {
+	Consumable maintenance.Consumable `json:"consumable"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "github.com/shivanshvij/flux/pkg/queue"
+
+type QueueJob struct {
+	ID          string            `json:"id"`
+	Filename    string            `json:"filename"`
+	Size        int64             `json:"size"`
+	Priority    int               `json:"priority"`
+	Constraints queue.Constraints `json:"constraints"`
+	State       queue.State       `json:"state"`
+	MachineID   string            `json:"machine_id,omitempty"`
+	TaskID      string            `json:"task_id,omitempty"`
+	Attempts    int               `json:"attempts"`
+	MaxAttempts int               `json:"max_attempts"`
+	Error       string            `json:"error,omitempty"`
+	CreatedAt   int64             `json:"created_at"`
+	StartedAt   int64             `json:"started_at,omitempty"`
+	FinishedAt  int64             `json:"finished_at,omitempty"`
+}
+
+func NewQueueJob(j *queue.Job) QueueJob {
+	job := QueueJob{
+		ID:          j.ID,
+		Filename:    j.Filename,
+		Size:        j.Size,
+		Priority:    j.Priority,
+		Constraints: j.Constraints,
+		State:       j.State,
+		MachineID:   j.MachineID,
+		TaskID:      j.TaskID,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		Error:       j.Error,
+		CreatedAt:   j.CreatedAt.UnixMilli(),
+	}
+	if !j.StartedAt.IsZero() {
+		job.StartedAt = j.StartedAt.UnixMilli()
+	}
+	if !j.FinishedAt.IsZero() {
+		job.FinishedAt = j.FinishedAt.UnixMilli()
+	}
+	return job
+}
+
+type QueueResponse struct {
+	Jobs []QueueJob `json:"jobs"`
+}
+
+type QueueJobResponse struct {
+	Job QueueJob `json:"job"`
+}
+
+type QueueUpdateRequest struct {
+	Priority int `json:"priority"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "github.com/shivanshvij/flux/pkg/webhook"
+
+type Webhook struct {
+	ID        string          `json:"id"`
+	URL       string          `json:"url"`
+	Events    []webhook.Event `json:"events"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+func NewWebhook(w *webhook.Webhook) Webhook {
+	events := w.Events
+	if events == nil {
+		events = []webhook.Event{}
+	}
+	return Webhook{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    events,
+		CreatedAt: w.CreatedAt.UnixMilli(),
+	}
+}
+
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type WebhookResponse struct {
+	Webhook Webhook `json:"webhook"`
+}
+
+type WebhookCreateRequest struct {
+	URL    string          `json:"url"`
+	Events []webhook.Event `json:"events"` // Events the webhook is sent, every event if empty
+	Secret string          `json:"secret"` // Secret the payloads are signed with, generated if empty
+}
+
+type WebhookCreateResponse struct {
+	Webhook Webhook `json:"webhook"`
+	Secret  string  `json:"secret"` // Only ever returned when the webhook is created
+}
+
+type WebhookDelivery struct {
+	ID         string        `json:"id"`
+	Event      webhook.Event `json:"event"`
+	Time       int64         `json:"time"`
+	Attempts   int           `json:"attempts"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Succeeded  bool          `json:"succeeded"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
@@ -0,0 +1,352 @@
+package queue
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/queue"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+type Queue struct {
+	logger types.Logger
+	app    *fiber.App
+
+	queue *queue.Queue
+}
+
+func New(queue *queue.Queue, logger types.Logger) *Queue {
+	i := &Queue{
+		logger: logger.SubLogger("queue"),
+		app:    utils.DefaultFiberApp(1024 * 1024 * 500),
+		queue:  queue,
+	}
+
+	i.init()
+
+	return i
+}
+
+func (a *Queue) init() {
+	a.logger.Debug().Msg("initializing")
+	a.app.Get("/", a.Jobs)
+	a.app.Post("/", a.Submit)
+	a.app.Get("/:id", a.Job)
+	a.app.Get("/:id/thumbnail", a.Thumbnail)
+	a.app.Patch("/:id", a.Update)
+	a.app.Delete("/:id", a.Delete)
+	a.app.Post("/:id/hold", a.Hold)
+	a.app.Post("/:id/release", a.Release)
+	a.app.Post("/:id/retry", a.Retry)
+	a.app.Post("/:id/cancel", a.Cancel)
+}
+
+// Jobs godoc
+// @Description  Lists every job in the queue in the order they are scheduled in
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Success      200  {object} models.QueueResponse
+// @Router       /queue [get]
+func (a *Queue) Jobs(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Jobs request from %s", ctx.IP())
+
+	jobs := a.queue.Jobs()
+	res := &models.QueueResponse{
+		Jobs: make([]models.QueueJob, len(jobs)),
+	}
+	for i := range jobs {
+		res.Jobs[i] = models.NewQueueJob(&jobs[i])
+	}
+
+	return ctx.JSON(res)
+}
+
+// Submit godoc
+// @Description  Submits a sliced file to the queue, to be printed by the next idle machine that satisfies its constraints
+// @Tags         queue
+// @Accept       multipart/form-data
+// @Produce      application/json
+// @Param        file formData file true "File to print"
+// @Param        priority formData int false "Jobs with a higher priority are scheduled first"
+// @Param        max_attempts formData int false "Number of times the job is attempted before it fails"
+// @Param        hold formData bool false "Submit the job held, so that it is not scheduled until it is released"
+// @Param        model formData string false "Machine model the job must be printed on"
+// @Param        resolution formData string false "Resolution of the machine the job must be printed on"
+// @Param        file_type formData string false "File type the machine must support, defaults to the file extension"
+// @Param        machines formData []string false "IDs of the machines the job may be printed on" collectionFormat(multi)
+// @Param        tags formData []string false "Tags the machine the job is printed on must have" collectionFormat(multi)
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      500  {string} string
+// @Router       /queue [post]
+func (a *Queue) Submit(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Submit request from %s", ctx.IP())
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse form")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse form")
+	}
+
+	files := form.File["file"]
+	if len(files) != 1 || files[0].Filename == "" || files[0].Size <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+	}
+	header := files[0]
+
+	options := queue.SubmitOptions{
+		Constraints: queue.Constraints{
+			Model:      ctx.FormValue("model"),
+			Resolution: ctx.FormValue("resolution"),
+			FileType:   sdcp.SupportedFileType(ctx.FormValue("file_type")),
+			Machines:   form.Value["machines"],
+			Tags:       form.Value["tags"],
+		},
+	}
+	if v := ctx.FormValue("priority"); v != "" {
+		options.Priority, err = strconv.Atoi(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid priority")
+		}
+	}
+	if v := ctx.FormValue("max_attempts"); v != "" {
+		options.MaxAttempts, err = strconv.Atoi(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid max_attempts")
+		}
+	}
+	if v := ctx.FormValue("hold"); v != "" {
+		options.Hold, err = strconv.ParseBool(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid hold")
+		}
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to open file")
+		return ctx.Status(fiber.StatusInternalServerError).SendString("failed to open file")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	j, err := a.queue.Submit(f, header.Filename, header.Size, options)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return ctx.JSON(&models.QueueJobResponse{
+		Job: models.NewQueueJob(j),
+	})
+}
+
+// Job godoc
+// @Description  Retrieves a job in the queue
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Router       /queue/{id} [get]
+func (a *Queue) Job(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Job request from %s", ctx.IP())
+	return a.respond(ctx, a.queue.Job)
+}
+
+// Thumbnail godoc
+// @Description  Retrieves the preview image of the sliced file of a job in the queue
+// @Tags         queue
+// @Produce      image/png
+// @Param        id path string true "id"
+// @Success      200  {file} file
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /queue/{id}/thumbnail [get]
+func (a *Queue) Thumbnail(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Thumbnail request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	thumbnail, err := a.queue.Thumbnail(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	data, err := thumbnail.PNG()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to encode thumbnail")
+		return ctx.Status(fiber.StatusInternalServerError).SendString("failed to encode thumbnail")
+	}
+
+	ctx.Type("png")
+	return ctx.Send(data)
+}
+
+// Update godoc
+// @Description  Changes the priority of a job in the queue
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Param        request  body models.QueueUpdateRequest true  "Queue Update Request"
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Router       /queue/{id} [patch]
+func (a *Queue) Update(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Update request from %s", ctx.IP())
+
+	body := new(models.QueueUpdateRequest)
+	err := ctx.BodyParser(body)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse body")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	}
+
+	return a.respond(ctx, func(id string) (*queue.Job, error) {
+		return a.queue.SetPriority(id, body.Priority)
+	})
+}
+
+// Delete godoc
+// @Description  Removes a job that is not assigned to a machine from the queue
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Router       /queue/{id} [delete]
+func (a *Queue) Delete(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Delete request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	err := a.queue.Delete(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// Hold godoc
+// @Description  Holds a queued job, so that it is not scheduled until it is released
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Router       /queue/{id}/hold [post]
+func (a *Queue) Hold(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Hold request from %s", ctx.IP())
+	return a.respond(ctx, a.queue.Hold)
+}
+
+// Release godoc
+// @Description  Releases a held job, so that it can be scheduled
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Router       /queue/{id}/release [post]
+func (a *Queue) Release(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Release request from %s", ctx.IP())
+	return a.respond(ctx, a.queue.Release)
+}
+
+// Retry godoc
+// @Description  Queues a failed or cancelled job again
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Router       /queue/{id}/retry [post]
+func (a *Queue) Retry(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Retry request from %s", ctx.IP())
+	return a.respond(ctx, a.queue.Retry)
+}
+
+// Cancel godoc
+// @Description  Cancels a job, stopping its upload or print if it is assigned to a machine
+// @Tags         queue
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.QueueJobResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      409  {string} string
+// @Failure      500  {string} string
+// @Router       /queue/{id}/cancel [post]
+func (a *Queue) Cancel(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Cancel request from %s", ctx.IP())
+	return a.respond(ctx, a.queue.Cancel)
+}
+
+func (a *Queue) App() *fiber.App {
+	return a.app
+}
+
+// respond applies action to the job with the id in the path, and responds with the resulting job
+func (a *Queue) respond(ctx *fiber.Ctx, action func(id string) (*queue.Job, error)) error {
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	j, err := action(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return ctx.JSON(&models.QueueJobResponse{
+		Job: models.NewQueueJob(j),
+	})
+}
+
+func errorStatus(err error) error {
+	switch {
+	case errors.Is(err, queue.ErrJobNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "job not found")
+	case errors.Is(err, queue.ErrNoThumbnail):
+		return fiber.NewError(fiber.StatusNotFound, queue.ErrNoThumbnail.Error())
+	case errors.Is(err, queue.ErrInvalidState):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, queue.ErrInvalidFile):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
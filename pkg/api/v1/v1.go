@@ -9,8 +9,16 @@ import (
 	"github.com/shivanshvij/flux/internal/utils"
 	"github.com/shivanshvij/flux/pkg/api/v1/discovery"
 	"github.com/shivanshvij/flux/pkg/api/v1/docs"
+	"github.com/shivanshvij/flux/pkg/api/v1/events"
+	"github.com/shivanshvij/flux/pkg/api/v1/maintenance"
 	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/api/v1/queue"
+	"github.com/shivanshvij/flux/pkg/api/v1/webhooks"
 	"github.com/shivanshvij/flux/pkg/sdcp"
+
+	maintenanceTracker "github.com/shivanshvij/flux/pkg/maintenance"
+	jobQueue "github.com/shivanshvij/flux/pkg/queue"
+	"github.com/shivanshvij/flux/pkg/webhook"
 )
 
 //go:generate go run -mod=mod github.com/swaggo/swag/cmd/swag@v1.16.3 init -g v1.go -o docs --pd --instanceName api -d ./
@@ -18,14 +26,20 @@ type V1 struct {
 	logger types.Logger
 	app    *fiber.App
 
-	sdcp *sdcp.SDCP
+	sdcp        *sdcp.SDCP
+	maintenance *maintenanceTracker.Tracker
+	queue       *jobQueue.Queue
+	webhooks    *webhook.Manager
 }
 
-func New(sdcp *sdcp.SDCP, logger types.Logger) *V1 {
+func New(sdcp *sdcp.SDCP, maintenance *maintenanceTracker.Tracker, queue *jobQueue.Queue, webhooks *webhook.Manager, logger types.Logger) *V1 {
 	v := &V1{
-		logger: logger.SubLogger("v1"),
-		app:    utils.DefaultFiberApp(1024 * 1024 * 500),
-		sdcp:   sdcp,
+		logger:      logger.SubLogger("v1"),
+		app:         utils.DefaultFiberApp(1024 * 1024 * 500),
+		sdcp:        sdcp,
+		maintenance: maintenance,
+		queue:       queue,
+		webhooks:    webhooks,
 	}
 
 	v.init()
@@ -39,8 +53,13 @@ func New(sdcp *sdcp.SDCP, logger types.Logger) *V1 {
 // @license.name Apache 2.0
 // @license.url https://www.apache.org/licenses/LICENSE-2.0.html
 // @host localhost:8080
-// @schemes https
+// @schemes http https
 // @BasePath /v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token created with flux token create, sent as "Bearer <token>"
+// @security BearerAuth
 func (v *V1) init() {
 	v.logger.Debug().Msg("initializing")
 
@@ -51,6 +70,10 @@ func (v *V1) init() {
 
 	v.app.Mount("/discovery", discovery.New(v.logger).App())
 	v.app.Mount("/machine", machine.New(v.sdcp, v.logger).App())
+	v.app.Mount("/events", events.New(v.sdcp, v.logger).App())
+	v.app.Mount("/maintenance", maintenance.New(v.maintenance, v.logger).App())
+	v.app.Mount("/queue", queue.New(v.queue, v.logger).App())
+	v.app.Mount("/webhooks", webhooks.New(v.webhooks, v.logger).App())
 
 	v.app.Get("/health", v.Health)
 }
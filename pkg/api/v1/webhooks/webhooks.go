@@ -0,0 +1,204 @@
+package webhooks
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/api/v1/models"
+	"github.com/shivanshvij/flux/pkg/webhook"
+)
+
+type Webhooks struct {
+	logger types.Logger
+	app    *fiber.App
+
+	manager *webhook.Manager
+}
+
+func New(manager *webhook.Manager, logger types.Logger) *Webhooks {
+	i := &Webhooks{
+		logger:  logger.SubLogger("webhooks"),
+		app:     utils.DefaultFiberApp(),
+		manager: manager,
+	}
+
+	i.init()
+
+	return i
+}
+
+func (a *Webhooks) init() {
+	a.logger.Debug().Msg("initializing")
+	a.app.Get("/", a.Webhooks)
+	a.app.Post("/", a.Create)
+	a.app.Get("/:id", a.Webhook)
+	a.app.Delete("/:id", a.Delete)
+	a.app.Get("/:id/deliveries", a.Deliveries)
+}
+
+// Webhooks godoc
+// @Description  Lists every webhook, without their secrets
+// @Tags         webhooks
+// @Accept       application/json
+// @Produce      application/json
+// @Success      200  {object} models.WebhooksResponse
+// @Router       /webhooks [get]
+func (a *Webhooks) Webhooks(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Webhooks request from %s", ctx.IP())
+
+	webhooks := a.manager.Webhooks()
+	res := &models.WebhooksResponse{
+		Webhooks: make([]models.Webhook, len(webhooks)),
+	}
+	for i := range webhooks {
+		res.Webhooks[i] = models.NewWebhook(&webhooks[i])
+	}
+
+	return ctx.JSON(res)
+}
+
+// Create godoc
+// @Description  Creates a webhook that is sent print lifecycle and machine connection events. Payloads are signed with the secret of the webhook, which is only returned by this request.
+// @Tags         webhooks
+// @Accept       application/json
+// @Produce      application/json
+// @Param        request  body models.WebhookCreateRequest true  "Webhook Create Request"
+// @Success      200  {object} models.WebhookCreateResponse
+// @Failure      400  {string} string
+// @Failure      500  {string} string
+// @Router       /webhooks [post]
+func (a *Webhooks) Create(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Create request from %s", ctx.IP())
+
+	body := new(models.WebhookCreateRequest)
+	err := ctx.BodyParser(body)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to parse body")
+		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	}
+
+	w, err := a.manager.Create(body.URL, body.Events, body.Secret)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return ctx.JSON(&models.WebhookCreateResponse{
+		Webhook: models.NewWebhook(w),
+		Secret:  w.Secret,
+	})
+}
+
+// Webhook godoc
+// @Description  Retrieves a webhook, without its secret
+// @Tags         webhooks
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.WebhookResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Router       /webhooks/{id} [get]
+func (a *Webhooks) Webhook(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Webhook request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	w, err := a.manager.Webhook(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return ctx.JSON(&models.WebhookResponse{
+		Webhook: models.NewWebhook(w),
+	})
+}
+
+// Delete godoc
+// @Description  Deletes a webhook
+// @Tags         webhooks
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Failure      500  {string} string
+// @Router       /webhooks/{id} [delete]
+func (a *Webhooks) Delete(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Delete request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	err := a.manager.Delete(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// Deliveries godoc
+// @Description  Retrieves the most recent deliveries to a webhook, oldest first
+// @Tags         webhooks
+// @Accept       application/json
+// @Produce      application/json
+// @Param        id path string true "id"
+// @Success      200  {object} models.WebhookDeliveriesResponse
+// @Failure      400  {string} string
+// @Failure      404  {string} string
+// @Router       /webhooks/{id}/deliveries [get]
+func (a *Webhooks) Deliveries(ctx *fiber.Ctx) error {
+	a.logger.Debug().Msgf("received Deliveries request from %s", ctx.IP())
+
+	id := ctx.Params("id")
+	if id == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	deliveries, err := a.manager.Deliveries(id)
+	if err != nil {
+		return errorStatus(err)
+	}
+
+	res := &models.WebhookDeliveriesResponse{
+		Deliveries: make([]models.WebhookDelivery, len(deliveries)),
+	}
+	for i, d := range deliveries {
+		res.Deliveries[i] = models.WebhookDelivery{
+			ID:         d.ID,
+			Event:      d.Event,
+			Time:       d.Time.UnixMilli(),
+			Attempts:   d.Attempts,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			Succeeded:  d.Succeeded,
+		}
+	}
+
+	return ctx.JSON(res)
+}
+
+func (a *Webhooks) App() *fiber.App {
+	return a.app
+}
+
+func errorStatus(err error) error {
+	switch {
+	case errors.Is(err, webhook.ErrWebhookNotFound):
+		return fiber.NewError(fiber.StatusNotFound, webhook.ErrWebhookNotFound.Error())
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrInvalidEvent):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,263 @@
+// Package auth authenticates requests to the Flux API with bearer tokens. Tokens are only ever
+// stored hashed, and each token is granted a scope that limits what it can be used for.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shivanshvij/flux/internal/utils"
+)
+
+const (
+	tokenPrefix = "flux_"
+	tokenSize   = 32
+)
+
+var (
+	ErrReadFailed    = errors.New("failed to read tokens file")
+	ErrWriteFailed   = errors.New("failed to write tokens file")
+	ErrTokenNotFound = errors.New("token not found")
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrInvalidScope  = errors.New("invalid scope")
+	ErrInvalidName   = errors.New("invalid token name")
+	ErrCreateFailed  = errors.New("failed to generate token")
+)
+
+type Scope string
+
+const (
+	ScopeNone    Scope = ""        // Required by requests that do not need a token
+	ScopeRead    Scope = "read"    // Retrieve the state of machines, jobs and alerts
+	ScopeControl Scope = "control" // Control machines and the queue, in addition to ScopeRead
+	ScopeAdmin   Scope = "admin"   // Register machines and configure webhooks, in addition to ScopeControl
+)
+
+// Scopes lists every scope that can be granted to a token, from least to most privileged
+var Scopes = []Scope{ScopeRead, ScopeControl, ScopeAdmin}
+
+func (s Scope) Valid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+// Allows returns true if a token with scope s may make a request that requires the given scope
+func (s Scope) Allows(required Scope) bool {
+	return slices.Index(Scopes, s) >= slices.Index(Scopes, required)
+}
+
+// Token is an API token. Only the SHA-256 hash of the token is kept, so it cannot be recovered.
+type Token struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scope     Scope     `json:"scope"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store keeps API tokens in a local file. The file is reloaded whenever it changes, so that tokens
+// created or revoked by another process take effect without a restart.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	tokens  []Token
+	modTime time.Time
+	size    int64
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Enabled returns true if any token exists. Requests are only authenticated once a token has been created.
+func (s *Store) Enabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	return len(s.tokens) > 0
+}
+
+// Tokens returns every token
+func (s *Store) Tokens() []Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	return slices.Clone(s.tokens)
+}
+
+// Create creates a token with the given name and scope, returning the token and its secret value,
+// which is never stored and must be given to the user now
+func (s *Store) Create(name string, scope Scope) (*Token, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrInvalidName
+	}
+	if !scope.Valid() {
+		return nil, "", ErrInvalidScope
+	}
+	b := make([]byte, tokenSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, "", errors.Join(ErrCreateFailed, err)
+	}
+	secret := tokenPrefix + hex.EncodeToString(b)
+
+	t := Token{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Scope:     scope,
+		Hash:      hash(secret),
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.load()
+	if err != nil {
+		return nil, "", err
+	}
+	s.tokens = append(s.tokens, t)
+	err = s.write()
+	if err != nil {
+		s.tokens = s.tokens[:len(s.tokens)-1]
+		return nil, "", err
+	}
+	return &t, secret, nil
+}
+
+// Revoke deletes the token with the given ID, or the only token with the given name
+func (s *Store) Revoke(idOrName string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(s.tokens, func(t Token) bool {
+		return t.ID == idOrName
+	})
+	if i < 0 {
+		for j, t := range s.tokens {
+			if t.Name == idOrName {
+				if i >= 0 {
+					// Names are not unique, so an ambiguous name does not revoke anything
+					return nil, ErrTokenNotFound
+				}
+				i = j
+			}
+		}
+	}
+	if i < 0 {
+		return nil, ErrTokenNotFound
+	}
+	t := s.tokens[i]
+	s.tokens = slices.Delete(s.tokens, i, i+1)
+	err = s.write()
+	if err != nil {
+		s.tokens = slices.Insert(s.tokens, i, t)
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Authenticate returns the token with the given secret value
+func (s *Store) Authenticate(secret string) (*Token, error) {
+	h := []byte(hash(secret))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare(h, []byte(t.Hash)) == 1 {
+			return &t, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// reload loads the tokens file if it changed since it was last loaded, keeping the current tokens
+// if it cannot be read, s.mu must be held
+func (s *Store) reload() {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.tokens = nil
+			s.modTime = time.Time{}
+			s.size = 0
+		}
+		return
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return
+	}
+	_ = s.load()
+}
+
+// load reads the tokens file, s.mu must be held
+func (s *Store) load() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.tokens = nil
+			s.modTime = time.Time{}
+			s.size = 0
+			return nil
+		}
+		return errors.Join(ErrReadFailed, err)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return errors.Join(ErrReadFailed, err)
+	}
+	var tokens []Token
+	err = json.Unmarshal(data, &tokens)
+	if err != nil {
+		return errors.Join(ErrReadFailed, err)
+	}
+	s.tokens = tokens
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return nil
+}
+
+// write persists the tokens, s.mu must be held
+func (s *Store) write() error {
+	data, err := json.Marshal(s.tokens)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	err = utils.WriteFileAtomic(s.path, data)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	info, err := os.Stat(s.path)
+	if err == nil {
+		s.modTime = info.ModTime()
+		s.size = info.Size()
+	}
+	return nil
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	logger := logging.Test(t, logging.Slog, t.Name())
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(Middleware(store, func(ctx *fiber.Ctx) Scope {
+		switch {
+		case ctx.Path() == "/health":
+			return ScopeNone
+		case ctx.Method() == fiber.MethodGet:
+			return ScopeRead
+		default:
+			return ScopeControl
+		}
+	}, logger))
+	app.Get("/health", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/status", func(ctx *fiber.Ctx) error {
+		return ctx.SendString(FromContext(ctx).Name)
+	})
+	app.Post("/print", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	request := func(method string, target string, header string, value string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	// Requests are not authenticated until a token exists
+	require.Equal(t, http.StatusOK, request(fiber.MethodPost, "/print", "", ""))
+
+	_, _, err = store.Create(" ", ScopeRead)
+	require.ErrorIs(t, err, ErrInvalidName)
+	_, _, err = store.Create("dashboard", "root")
+	require.ErrorIs(t, err, ErrInvalidScope)
+	read, readSecret, err := store.Create("dashboard", ScopeRead)
+	require.NoError(t, err)
+	_, controlSecret, err := store.Create("automation", ScopeControl)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, request(fiber.MethodGet, "/health", "", ""))
+	require.Equal(t, http.StatusUnauthorized, request(fiber.MethodGet, "/status", "", ""))
+	require.Equal(t, http.StatusUnauthorized, request(fiber.MethodGet, "/status", fiber.HeaderAuthorization, "Bearer flux_invalid"))
+	require.Equal(t, http.StatusOK, request(fiber.MethodGet, "/status", fiber.HeaderAuthorization, "Bearer "+readSecret))
+	require.Equal(t, http.StatusOK, request(fiber.MethodGet, "/status?"+QueryAccessToken+"="+readSecret, "", ""))
+	require.Equal(t, http.StatusForbidden, request(fiber.MethodPost, "/print", HeaderAPIKey, readSecret))
+	require.Equal(t, http.StatusOK, request(fiber.MethodPost, "/print", HeaderAPIKey, controlSecret))
+
+	// Tokens revoked through another store, such as the one used by the CLI, are rejected immediately
+	other, err := NewStore(path)
+	require.NoError(t, err)
+	require.Len(t, other.Tokens(), 2)
+	require.NotContains(t, other.Tokens()[0].Hash, readSecret)
+	revoked, err := other.Revoke(read.ID)
+	require.NoError(t, err)
+	require.Equal(t, "dashboard", revoked.Name)
+	require.Equal(t, http.StatusUnauthorized, request(fiber.MethodGet, "/status", fiber.HeaderAuthorization, "Bearer "+readSecret))
+	_, err = other.Revoke(read.ID)
+	require.ErrorIs(t, err, ErrTokenNotFound)
+
+	_, err = store.Revoke("automation")
+	require.NoError(t, err)
+	require.False(t, store.Enabled())
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/loopholelabs/logging/types"
+)
+
+const (
+	HeaderAPIKey     = "X-API-Key"
+	QueryAccessToken = "access_token" // Browsers cannot set headers on EventSource and WebSocket connections
+
+	localsToken = "auth.token"
+)
+
+// Middleware authenticates every request that requires a scope, as returned by required, once a token
+// exists. Tokens are read from the Authorization header as a bearer token, the X-API-Key header, or the
+// access_token query parameter. Requests that change state are logged along with the token that made them.
+func Middleware(store *Store, required func(ctx *fiber.Ctx) Scope, logger types.Logger) fiber.Handler {
+	logger = logger.SubLogger("auth")
+	return func(ctx *fiber.Ctx) error {
+		scope := required(ctx)
+		if scope == ScopeNone || !store.Enabled() {
+			return ctx.Next()
+		}
+
+		secret := credentials(ctx)
+		if secret == "" {
+			ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return fiber.NewError(fiber.StatusUnauthorized, "missing api token")
+		}
+		t, err := store.Authenticate(secret)
+		if err != nil {
+			logger.Warn().Str("ip", ctx.IP()).Str("path", ctx.Path()).Msg("rejected request with invalid api token")
+			ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid api token")
+		}
+		if !t.Scope.Allows(scope) {
+			logger.Warn().Str("token", t.ID).Str("name", t.Name).Str("path", ctx.Path()).Msgf("rejected request requiring %s scope", scope)
+			return fiber.NewError(fiber.StatusForbidden, "api token does not have the "+string(scope)+" scope")
+		}
+
+		ctx.Locals(localsToken, t)
+		err = ctx.Next()
+		if scope != ScopeRead {
+			code := ctx.Response().StatusCode()
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				code = fiberErr.Code
+			} else if err != nil {
+				code = fiber.StatusInternalServerError
+			}
+			logger.Info().Str("token", t.ID).Str("name", t.Name).Str("ip", ctx.IP()).Int("status", code).Msgf("%s %s", ctx.Method(), ctx.Path())
+		}
+		return err
+	}
+}
+
+// FromContext returns the token that authenticated a request, or nil if the request was not authenticated
+func FromContext(ctx *fiber.Ctx) *Token {
+	t, _ := ctx.Locals(localsToken).(*Token)
+	return t
+}
+
+func credentials(ctx *fiber.Ctx) string {
+	if header := ctx.Get(fiber.HeaderAuthorization); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if key := ctx.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+	return ctx.Query(QueryAccessToken)
+}
@@ -0,0 +1,144 @@
+// Package discovery periodically discovers printers on the local network in the background, registering
+// new printers that a policy allows and following registered machines whose IP address changes.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	DefaultInterval = time.Minute
+	DefaultTimeout  = 5 * time.Second
+)
+
+type Options struct {
+	Interval     time.Duration // Time between discover messages
+	Timeout      time.Duration // Time answers to a discover message are waited for
+	AutoRegister bool          // Register new printers, otherwise only the addresses of registered machines are updated
+	Allow        Policy        // Printers that are acted on, every printer if empty
+	Deny         Policy        // Printers that are never acted on, even if they are allowed
+	Port         int           // Port the SDCP endpoints of registered printers listen on, defaults to sdcp.APIPort
+	Address      *net.UDPAddr  // Address discover messages are sent to, defaults to the broadcast address
+}
+
+func (o *Options) defaults() {
+	if o.Interval <= 0 {
+		o.Interval = DefaultInterval
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.Port == 0 {
+		o.Port = sdcp.APIPort
+	}
+	if o.Address == nil {
+		o.Address = &net.UDPAddr{IP: net.ParseIP(sdcp.BroadcastIP), Port: sdcp.BroadcastPort}
+	}
+}
+
+// Service sends a discover message every interval and registers or updates the machines that answer
+type Service struct {
+	logger  types.Logger
+	sdcp    *sdcp.SDCP
+	options Options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(s *sdcp.SDCP, options Options, logger types.Logger) *Service {
+	options.defaults()
+	d := &Service{
+		logger:  logger.SubLogger("discovery"),
+		sdcp:    s,
+		options: options,
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	return d
+}
+
+// Start discovers printers immediately and then every interval
+func (d *Service) Start() {
+	d.logger.Info().Str("interval", d.options.Interval.String()).Bool("auto_register", d.options.AutoRegister).Msg("starting background discovery")
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.options.Interval)
+		defer ticker.Stop()
+		for {
+			d.Discover(d.ctx)
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (d *Service) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Discover sends a single discover message and acts on every allowed printer that answers it
+func (d *Service) Discover(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+	discovered, err := sdcp.DiscoverAddress(d.logger, ctx, d.options.Address)
+	if err != nil {
+		d.logger.Warn().Err(err).Msg("failed to discover printers")
+		return
+	}
+	for _, message := range discovered {
+		if d.ctx.Err() != nil {
+			return
+		}
+		d.apply(message.Data)
+	}
+}
+
+// Allowed returns true if the policy allows the printer to be registered or updated
+func (d *Service) Allowed(data sdcp.DiscoverData) bool {
+	return (d.options.Allow.Empty() || d.options.Allow.Matches(data)) && !d.options.Deny.Matches(data)
+}
+
+func (d *Service) apply(data sdcp.DiscoverData) {
+	if data.MainboardID == "" || data.MainboardIP == "" {
+		return
+	}
+	logger := d.logger.With().Str("id", data.MainboardID).Str("ip", data.MainboardIP).Logger()
+	if !d.Allowed(data) {
+		logger.Debug().Msg("ignoring printer denied by policy")
+		return
+	}
+
+	if _, ok := d.sdcp.GetMachine(data.MainboardID); ok {
+		_, err := d.sdcp.UpdateDiscovered(data)
+		if err != nil && !errors.Is(err, sdcp.ErrNotRegistered) {
+			logger.Warn().Err(err).Msg("failed to update address of discovered machine")
+		}
+		return
+	}
+
+	if !d.options.AutoRegister {
+		return
+	}
+	err := d.sdcp.RegisterDiscovered(data, d.options.Port)
+	if err != nil {
+		if !errors.Is(err, sdcp.ErrAlreadyRegistered) {
+			logger.Warn().Err(err).Msg("failed to register discovered printer")
+		}
+		return
+	}
+	logger.Info().Str("name", data.MachineName).Str("model", data.MachineModel).Msg("registered discovered printer")
+}
@@ -0,0 +1,100 @@
+package discovery
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/registry"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/sdcp/sdcptest"
+)
+
+func TestPolicy(t *testing.T) {
+	data := sdcp.DiscoverData{MainboardID: "0123456789abcdef", MachineModel: "Saturn 4 Ultra", MainboardIP: "192.168.1.20"}
+	require.True(t, ParsePolicy(nil).Empty())
+	require.True(t, ParsePolicy([]string{"192.168.1.0/24"}).Matches(data))
+	require.True(t, ParsePolicy([]string{"192.168.1.20"}).Matches(data))
+	require.True(t, ParsePolicy([]string{"saturn 4 ultra"}).Matches(data))
+	require.True(t, ParsePolicy([]string{"10.0.0.0/8", "0123456789abcdef"}).Matches(data))
+	require.False(t, ParsePolicy([]string{"10.0.0.0/8", "Mars 5"}).Matches(data))
+}
+
+func TestDiscovery(t *testing.T) {
+	logger := logging.Test(t, logging.Slog, t.Name())
+	printer, err := sdcptest.NewPrinter(sdcptest.Config{DiscoveryAddress: "127.0.0.1:0"})
+	require.NoError(t, err)
+	t.Cleanup(printer.Close)
+
+	options := Options{
+		Interval:     100 * time.Millisecond,
+		Timeout:      200 * time.Millisecond,
+		AutoRegister: true,
+		Port:         printer.Addr().Port,
+		Address:      printer.DiscoveryAddr(),
+	}
+	waitFor := func(subscription *sdcp.Subscription, eventType sdcp.DiscoveryEventType) *sdcp.DiscoveryEvent {
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case e := <-subscription.C:
+				if e.Type == sdcp.EventTypeDiscovery && e.Discovery.Type == eventType {
+					return e.Discovery
+				}
+			case <-timeout:
+				require.FailNow(t, "timed out waiting for discovery event")
+			}
+		}
+	}
+
+	// Denied printers are not registered
+	s := sdcp.New(logger, nil)
+	t.Cleanup(s.Close)
+	denied := options
+	denied.Deny = ParsePolicy([]string{"127.0.0.0/8"})
+	New(s, denied, logger).Discover(context.Background())
+	require.Empty(t, s.Machines())
+
+	subscription := s.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	d := New(s, options, logger)
+	d.Start()
+	registered := waitFor(subscription, sdcp.DiscoveryEventTypeRegistered)
+	d.Stop()
+	require.Equal(t, sdcptest.DefaultModel, registered.MachineModel)
+	m, ok := s.GetMachine(printer.ID())
+	require.True(t, ok)
+	require.Equal(t, sdcptest.DefaultName, m.Label())
+	require.Equal(t, sdcp.ConnectionStateOnline, m.State())
+
+	// Registered machines that were given a new address are reconnected to at that address
+	path := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, registry.NewFile(path).Put(sdcp.Registration{ID: printer.ID(), IP: "127.0.0.2", Port: printer.Addr().Port}))
+	restored := sdcp.New(logger, registry.NewFile(path))
+	t.Cleanup(restored.Close)
+	require.NoError(t, restored.Restore())
+	subscription = restored.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	m, ok = restored.GetMachine(printer.ID())
+	require.True(t, ok)
+
+	options.AutoRegister = false
+	New(restored, options, logger).Discover(context.Background())
+	changed := waitFor(subscription, sdcp.DiscoveryEventTypeAddressChanged)
+	require.Equal(t, "127.0.0.2", changed.PreviousIP)
+	require.Equal(t, "127.0.0.1", changed.IP)
+	require.Eventually(t, func() bool {
+		return m.State() == sdcp.ConnectionStateOnline
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, "127.0.0.1", m.IP())
+	updated, ok := restored.GetMachine(printer.ID())
+	require.True(t, ok)
+	require.Same(t, m, updated)
+
+	registrations, err := registry.NewFile(path).List()
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	require.Equal(t, "127.0.0.1", registrations[0].IP)
+}
@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// Policy selects printers by their mainboard ID, model or the subnet of their IP address
+type Policy struct {
+	MainboardIDs []string
+	Models       []string
+	Subnets      []*net.IPNet
+}
+
+// ParsePolicy parses a policy from a list of rules. Rules that are IP addresses or subnets in CIDR notation
+// select printers by address, and any other rule selects printers by their mainboard ID or model.
+func ParsePolicy(rules []string) Policy {
+	var p Policy
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if _, subnet, err := net.ParseCIDR(rule); err == nil {
+			p.Subnets = append(p.Subnets, subnet)
+			continue
+		}
+		if ip := net.ParseIP(rule); ip != nil {
+			bits := 8 * len(ip)
+			if v4 := ip.To4(); v4 != nil {
+				ip, bits = v4, 32
+			}
+			p.Subnets = append(p.Subnets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		p.MainboardIDs = append(p.MainboardIDs, rule)
+		p.Models = append(p.Models, rule)
+	}
+	return p
+}
+
+// Empty returns true if the policy has no rules
+func (p Policy) Empty() bool {
+	return len(p.MainboardIDs) == 0 && len(p.Models) == 0 && len(p.Subnets) == 0
+}
+
+// Matches returns true if any rule of the policy selects the printer
+func (p Policy) Matches(data sdcp.DiscoverData) bool {
+	if slices.Contains(p.MainboardIDs, data.MainboardID) {
+		return true
+	}
+	if slices.ContainsFunc(p.Models, func(model string) bool {
+		return strings.EqualFold(model, data.MachineModel)
+	}) {
+		return true
+	}
+	ip := net.ParseIP(data.MainboardIP)
+	return ip != nil && slices.ContainsFunc(p.Subnets, func(subnet *net.IPNet) bool {
+		return subnet.Contains(ip)
+	})
+}
@@ -0,0 +1,300 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	DefaultWarning        = 0.8
+	DefaultCritical       = 0.95
+	DefaultScreenLifetime = 2000 * time.Hour
+	DefaultWindow         = 14 * 24 * time.Hour
+
+	sampleInterval        = 10 * time.Minute
+	minimumForecastWindow = time.Hour
+	maxAlerts             = 100
+)
+
+var (
+	ErrReadFailed        = errors.New("failed to read maintenance file")
+	ErrWriteFailed       = errors.New("failed to write maintenance file")
+	ErrUnknownMachine    = errors.New("unknown machine")
+	ErrUnknownConsumable = errors.New("unknown consumable")
+	ErrNoUsage           = errors.New("no usage has been reported by the machine")
+)
+
+type Options struct {
+	Warning        float64       // Fraction of the service life at which a warning is raised
+	Critical       float64       // Fraction of the service life at which a critical alert is raised
+	ScreenLifetime time.Duration // Exposure time the screen is rated for, the printer does not report it
+	Window         time.Duration // Period of recent usage the remaining life is forecast from
+}
+
+func (o *Options) defaults() {
+	if o.Warning <= 0 {
+		o.Warning = DefaultWarning
+	}
+	if o.Critical <= 0 {
+		o.Critical = DefaultCritical
+	}
+	if o.ScreenLifetime <= 0 {
+		o.ScreenLifetime = DefaultScreenLifetime
+	}
+	if o.Window <= 0 {
+		o.Window = DefaultWindow
+	}
+}
+
+// baseline is the counter value of a consumable when it was last replaced
+type baseline struct {
+	Value      float64   `json:"value"`
+	ReplacedAt time.Time `json:"replaced_at"`
+	Alerted    Level     `json:"alerted"`
+}
+
+type machine struct {
+	Baselines map[Consumable]*baseline `json:"baselines"`
+	Samples   []Sample                 `json:"samples"`
+}
+
+// Tracker follows the wear counters pushed by every machine, keeping a history of recent usage
+// and the baseline of each consumable in a local file, and raises alerts as consumables wear out
+type Tracker struct {
+	logger  types.Logger
+	sdcp    *sdcp.SDCP
+	path    string
+	options Options
+
+	mu       sync.Mutex
+	machines map[string]*machine
+	alerts   []Alert
+
+	subscription *sdcp.Subscription
+	wg           sync.WaitGroup
+}
+
+func New(s *sdcp.SDCP, path string, options Options, logger types.Logger) (*Tracker, error) {
+	options.defaults()
+	t := &Tracker{
+		logger:   logger.SubLogger("maintenance"),
+		sdcp:     s,
+		path:     path,
+		options:  options,
+		machines: make(map[string]*machine),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Join(ErrReadFailed, err)
+		}
+	} else {
+		err = json.Unmarshal(data, &t.machines)
+		if err != nil {
+			return nil, errors.Join(ErrReadFailed, err)
+		}
+	}
+
+	return t, nil
+}
+
+// Start begins tracking the status pushed by every machine
+func (t *Tracker) Start() {
+	t.subscription = t.sdcp.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for e := range t.subscription.C {
+			if e.Type == sdcp.EventTypeStatus && e.Status != nil {
+				t.observe(e.MachineID, e.Time, e.Status)
+			}
+		}
+	}()
+}
+
+func (t *Tracker) Stop() {
+	if t.subscription != nil {
+		t.subscription.Close()
+	}
+	t.wg.Wait()
+}
+
+// Wear returns the wear of every consumable of a machine
+func (t *Tracker) Wear(machineID string) ([]Wear, error) {
+	m, ok := t.sdcp.GetMachine(machineID)
+	if !ok {
+		return nil, ErrUnknownMachine
+	}
+	limits := t.limits(m)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec, ok := t.machines[machineID]
+	if !ok || len(rec.Samples) == 0 {
+		return nil, ErrNoUsage
+	}
+	wear := make([]Wear, 0, len(Consumables))
+	for _, c := range Consumables {
+		wear = append(wear, t.wear(rec, c, limits[c]))
+	}
+	return wear, nil
+}
+
+// Replace records that a consumable of a machine was replaced, so that its wear is counted from
+// the machine's current usage counter
+func (t *Tracker) Replace(machineID string, consumable Consumable) error {
+	if !consumable.Valid() {
+		return ErrUnknownConsumable
+	}
+	m, ok := t.sdcp.GetMachine(machineID)
+	if !ok {
+		return ErrUnknownMachine
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec := t.machine(machineID)
+	current := newSample(time.Now(), m.Status())
+	if len(rec.Samples) > 0 && m.State() != sdcp.ConnectionStateOnline {
+		current = rec.Samples[len(rec.Samples)-1]
+	}
+	rec.Baselines[consumable] = &baseline{
+		Value:      current.counter(consumable),
+		ReplacedAt: time.Now(),
+		Alerted:    LevelOK,
+	}
+	t.logger.Info().Str("machine", machineID).Msgf("%s replaced", consumable)
+	return t.write()
+}
+
+// Alerts returns the most recent maintenance alerts of the given machines, or of every machine
+// if no machine IDs are given, oldest first
+func (t *Tracker) Alerts(machineIDs ...string) []Alert {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	alerts := make([]Alert, 0, len(t.alerts))
+	for _, a := range t.alerts {
+		if len(machineIDs) == 0 || slices.Contains(machineIDs, a.MachineID) {
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+func (t *Tracker) observe(machineID string, now time.Time, status *sdcp.Status) {
+	var limits map[Consumable]float64
+	if m, ok := t.sdcp.GetMachine(machineID); ok {
+		limits = t.limits(m)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec := t.machine(machineID)
+	sample := newSample(now, status)
+
+	changed := false
+	for _, c := range Consumables {
+		b := rec.baseline(c)
+		reset := sample.counter(c) < b.Value
+		if len(rec.Samples) > 0 && sample.counter(c) < rec.Samples[len(rec.Samples)-1].counter(c) {
+			reset = true
+		}
+		if reset {
+			// The counter was reset on the machine itself, which only happens when the consumable is replaced
+			*b = baseline{ReplacedAt: now, Alerted: LevelOK}
+			changed = true
+		}
+	}
+
+	if len(rec.Samples) == 0 || now.Sub(rec.Samples[len(rec.Samples)-1].Time) >= sampleInterval {
+		rec.Samples = append(rec.Samples, sample)
+		cutoff := now.Add(-t.options.Window)
+		i := 0
+		for i < len(rec.Samples)-1 && rec.Samples[i].Time.Before(cutoff) {
+			i++
+		}
+		rec.Samples = rec.Samples[i:]
+		changed = true
+	} else {
+		rec.Samples[len(rec.Samples)-1] = sample
+	}
+
+	for _, c := range Consumables {
+		w := t.wear(rec, c, limits[c])
+		b := rec.baseline(c)
+		if w.Level.severity() > b.Alerted.severity() {
+			a := Alert{MachineID: machineID, Time: now, Wear: w}
+			t.alerts = append(t.alerts, a)
+			if len(t.alerts) > maxAlerts {
+				t.alerts = t.alerts[len(t.alerts)-maxAlerts:]
+			}
+			t.logger.Warn().Str("machine", machineID).Msgf("maintenance required: %s", a)
+		}
+		if w.Level != LevelUnknown && w.Level != b.Alerted {
+			b.Alerted = w.Level
+			changed = true
+		}
+	}
+
+	if changed {
+		err := t.write()
+		if err != nil {
+			t.logger.Error().Err(err).Msg("failed to persist maintenance state")
+		}
+	}
+}
+
+func (t *Tracker) wear(rec *machine, c Consumable, limit float64) Wear {
+	return t.options.wear(c, *rec.baseline(c), rec.Samples[len(rec.Samples)-1], rec.Samples, limit)
+}
+
+func (t *Tracker) limits(m *sdcp.Machine) map[Consumable]float64 {
+	return map[Consumable]float64{
+		ConsumableReleaseFilm: float64(m.Attributes().ReleaseFilmMax),
+		ConsumableScreen:      t.options.ScreenLifetime.Seconds(),
+	}
+}
+
+func (t *Tracker) machine(machineID string) *machine {
+	rec, ok := t.machines[machineID]
+	if !ok {
+		rec = &machine{}
+		t.machines[machineID] = rec
+	}
+	if rec.Baselines == nil {
+		rec.Baselines = make(map[Consumable]*baseline)
+	}
+	return rec
+}
+
+func (m *machine) baseline(c Consumable) *baseline {
+	b, ok := m.Baselines[c]
+	if !ok {
+		// Until a replacement is recorded, the machine's counter is assumed to start at the current consumable
+		b = &baseline{Alerted: LevelOK}
+		m.Baselines[c] = b
+	}
+	return b
+}
+
+func (t *Tracker) write() error {
+	data, err := json.Marshal(t.machines)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	err = utils.WriteFileAtomic(t.path, data)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	return nil
+}
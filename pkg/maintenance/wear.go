@@ -0,0 +1,143 @@
+package maintenance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+type Consumable string
+
+const (
+	ConsumableReleaseFilm Consumable = "release_film" // FEP release film, worn by every layer lift
+	ConsumableScreen      Consumable = "screen"       // Exposure LCD, worn by exposure time
+)
+
+var Consumables = []Consumable{ConsumableReleaseFilm, ConsumableScreen}
+
+func (c Consumable) Valid() bool {
+	switch c {
+	case ConsumableReleaseFilm, ConsumableScreen:
+		return true
+	}
+	return false
+}
+
+type Level string
+
+const (
+	LevelUnknown  Level = "unknown" // The service life of the consumable is not known
+	LevelOK       Level = "ok"
+	LevelWarning  Level = "warning"
+	LevelCritical Level = "critical"
+)
+
+func (l Level) severity() int {
+	switch l {
+	case LevelWarning:
+		return 1
+	case LevelCritical:
+		return 2
+	}
+	return 0
+}
+
+// Wear is the wear of a consumable since it was last replaced. Release film usage is counted
+// in uses and screen usage in seconds of exposure.
+type Wear struct {
+	Consumable Consumable
+	Used       float64
+	Limit      float64
+	Remaining  float64
+	Fraction   float64
+	Rate       float64   // Average usage per day over the forecast window
+	Exhausted  time.Time // Forecast time at which Remaining reaches zero, zero if there is no recent usage
+	ReplacedAt time.Time // Zero if the replacement was never recorded
+	Level      Level
+}
+
+// Alert is raised whenever the wear of a consumable crosses a maintenance threshold
+type Alert struct {
+	MachineID string
+	Time      time.Time
+	Wear      Wear
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s %s at %.0f%% of its service life", a.Wear.Consumable, a.Wear.Level, a.Wear.Fraction*100)
+}
+
+// Sample is a reading of the wear counters reported in a machine's Status
+type Sample struct {
+	Time        time.Time `json:"time"`
+	ReleaseFilm float64   `json:"release_film"`
+	PrintScreen float64   `json:"print_screen"`
+}
+
+func newSample(t time.Time, status *sdcp.Status) Sample {
+	return Sample{
+		Time:        t,
+		ReleaseFilm: float64(status.ReleaseFilm),
+		PrintScreen: status.PrintScreen,
+	}
+}
+
+func (s Sample) counter(c Consumable) float64 {
+	switch c {
+	case ConsumableReleaseFilm:
+		return s.ReleaseFilm
+	case ConsumableScreen:
+		return s.PrintScreen
+	}
+	return 0
+}
+
+// rate returns the average daily usage of a consumable across samples, accounting for counter resets
+func rate(samples []Sample, c Consumable) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	elapsed := samples[len(samples)-1].Time.Sub(samples[0].Time)
+	if elapsed < minimumForecastWindow {
+		return 0
+	}
+	var used float64
+	for i := 1; i < len(samples); i++ {
+		current, previous := samples[i].counter(c), samples[i-1].counter(c)
+		if current < previous {
+			// The counter was reset, so everything it has counted since was used after the reset
+			previous = 0
+		}
+		used += current - previous
+	}
+	return used / (elapsed.Hours() / 24)
+}
+
+func (o *Options) wear(c Consumable, baseline baseline, current Sample, samples []Sample, limit float64) Wear {
+	w := Wear{
+		Consumable: c,
+		Used:       max(current.counter(c)-baseline.Value, 0),
+		Limit:      limit,
+		Rate:       rate(samples, c),
+		ReplacedAt: baseline.ReplacedAt,
+		Level:      LevelUnknown,
+	}
+	if limit <= 0 {
+		return w
+	}
+	w.Remaining = max(limit-w.Used, 0)
+	w.Fraction = w.Used / limit
+	switch {
+	case w.Fraction >= o.Critical:
+		w.Level = LevelCritical
+	case w.Fraction >= o.Warning:
+		w.Level = LevelWarning
+	default:
+		w.Level = LevelOK
+	}
+	if w.Rate > 0 {
+		w.Exhausted = current.Time.Add(time.Duration(w.Remaining / w.Rate * float64(24*time.Hour)))
+	}
+	return w
+}
@@ -0,0 +1,44 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWear(t *testing.T) {
+	options := Options{}
+	options.defaults()
+
+	start := time.Now()
+	samples := []Sample{
+		{Time: start, ReleaseFilm: 1000},
+		{Time: start.Add(24 * time.Hour), ReleaseFilm: 1500},
+		{Time: start.Add(36 * time.Hour), ReleaseFilm: 100}, // Reset on the machine
+		{Time: start.Add(48 * time.Hour), ReleaseFilm: 600},
+	}
+	require.InDelta(t, 550, rate(samples, ConsumableReleaseFilm), 0.001)
+	require.Zero(t, rate(samples[:1], ConsumableReleaseFilm))
+
+	current := samples[len(samples)-1]
+	days := 3400.0 / 550
+	w := options.wear(ConsumableReleaseFilm, baseline{}, current, samples, 4000)
+	require.Equal(t, 600.0, w.Used)
+	require.Equal(t, 3400.0, w.Remaining)
+	require.Equal(t, LevelOK, w.Level)
+	require.WithinDuration(t, current.Time.Add(time.Duration(days*float64(24*time.Hour))), w.Exhausted, time.Second)
+
+	w = options.wear(ConsumableReleaseFilm, baseline{}, current, samples, 700)
+	require.Equal(t, LevelWarning, w.Level)
+
+	w = options.wear(ConsumableReleaseFilm, baseline{}, current, samples, 600)
+	require.Equal(t, LevelCritical, w.Level)
+	require.Zero(t, w.Remaining)
+
+	w = options.wear(ConsumableReleaseFilm, baseline{Value: 500}, current, samples, 4000)
+	require.Equal(t, 100.0, w.Used)
+
+	w = options.wear(ConsumableReleaseFilm, baseline{}, current, samples, 0)
+	require.Equal(t, LevelUnknown, w.Level)
+}
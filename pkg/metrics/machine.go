@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+var _ prometheus.Collector = (*machineCollector)(nil)
+
+var machineLabels = []string{"id", "name", "model"}
+
+func machineDesc(name string, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "machine", name), help, append(append([]string{}, machineLabels...), labels...), nil)
+}
+
+// machineCollector collects the latest Status and Attributes of every machine each time it is scraped
+type machineCollector struct {
+	sdcp *sdcp.SDCP
+
+	online                *prometheus.Desc
+	status                *prometheus.Desc
+	printStatus           *prometheus.Desc
+	uvledTemperature      *prometheus.Desc
+	uvledTemperatureMax   *prometheus.Desc
+	boxTemperature        *prometheus.Desc
+	boxTargetTemperature  *prometheus.Desc
+	printScreenSeconds    *prometheus.Desc
+	releaseFilmUses       *prometheus.Desc
+	releaseFilmMaxUses    *prometheus.Desc
+	currentLayer          *prometheus.Desc
+	totalLayers           *prometheus.Desc
+	elapsedSeconds        *prometheus.Desc
+	estimatedSeconds      *prometheus.Desc
+	remainingMemoryBits   *prometheus.Desc
+	videoStreams          *prometheus.Desc
+	videoStreamsMax       *prometheus.Desc
+	timeLapseEnabled      *prometheus.Desc
+	usbDiskConnected      *prometheus.Desc
+	cameraConnected       *prometheus.Desc
+	printErrorNumber      *prometheus.Desc
+	printProgressFraction *prometheus.Desc
+}
+
+func newMachineCollector(s *sdcp.SDCP) *machineCollector {
+	return &machineCollector{
+		sdcp:                  s,
+		online:                machineDesc("online", "Whether Flux is connected to the machine", "state"),
+		status:                machineDesc("status", "Current status of the machine, 1 for every status the machine is in", "status"),
+		printStatus:           machineDesc("print_status", "Current printing sub-status of the machine", "status"),
+		uvledTemperature:      machineDesc("uvled_temperature_celsius", "Current UVLED temperature"),
+		uvledTemperatureMax:   machineDesc("uvled_temperature_max_celsius", "Maximum operating temperature of the UVLED"),
+		boxTemperature:        machineDesc("box_temperature_celsius", "Current enclosure temperature"),
+		boxTargetTemperature:  machineDesc("box_target_temperature_celsius", "Target enclosure temperature"),
+		printScreenSeconds:    machineDesc("print_screen_seconds_total", "Total exposure screen usage time"),
+		releaseFilmUses:       machineDesc("release_film_uses_total", "Total release film usage count"),
+		releaseFilmMaxUses:    machineDesc("release_film_max_uses", "Maximum number of uses of the release film"),
+		currentLayer:          machineDesc("print_current_layer", "Current printing layer"),
+		totalLayers:           machineDesc("print_total_layers", "Total number of layers of the current print"),
+		elapsedSeconds:        machineDesc("print_elapsed_seconds", "Elapsed time of the current print"),
+		estimatedSeconds:      machineDesc("print_estimated_seconds", "Estimated total time of the current print"),
+		printProgressFraction: machineDesc("print_progress_ratio", "Fraction of the layers of the current print that have been printed"),
+		printErrorNumber:      machineDesc("print_error", "Error number of the current print, 0 if there is no error"),
+		remainingMemoryBits:   machineDesc("remaining_memory_bits", "Remaining file storage space"),
+		videoStreams:          machineDesc("video_streams", "Number of connected video streams"),
+		videoStreamsMax:       machineDesc("video_streams_max", "Maximum number of connected video streams"),
+		timeLapseEnabled:      machineDesc("time_lapse_enabled", "Whether time-lapse photography is enabled"),
+		usbDiskConnected:      machineDesc("usb_disk_connected", "Whether a USB drive is connected"),
+		cameraConnected:       machineDesc("camera_connected", "Whether the camera is connected"),
+	}
+}
+
+func (c *machineCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.online, c.status, c.printStatus, c.uvledTemperature, c.uvledTemperatureMax, c.boxTemperature,
+		c.boxTargetTemperature, c.printScreenSeconds, c.releaseFilmUses, c.releaseFilmMaxUses, c.currentLayer,
+		c.totalLayers, c.elapsedSeconds, c.estimatedSeconds, c.remainingMemoryBits, c.videoStreams,
+		c.videoStreamsMax, c.timeLapseEnabled, c.usbDiskConnected, c.cameraConnected, c.printErrorNumber,
+		c.printProgressFraction,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *machineCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.sdcp.Machines() {
+		state := m.State()
+		status := m.Status()
+		attributes := m.Attributes()
+		labels := []string{m.ID(), attributes.MachineName, attributes.MachineModel}
+
+		gauge := func(desc *prometheus.Desc, value float64, extra ...string) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append(append([]string{}, labels...), extra...)...)
+		}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, labels...)
+		}
+
+		gauge(c.online, boolean(state == sdcp.ConnectionStateOnline), string(state))
+		if state != sdcp.ConnectionStateOnline && attributes.MainboardID == "" {
+			// Nothing has been received from a machine that has never been online
+			continue
+		}
+
+		for _, s := range status.CurrentStatus {
+			gauge(c.status, 1, s.String())
+		}
+		gauge(c.printStatus, float64(status.PrintInfo.Status), status.PrintInfo.Status.String())
+		gauge(c.uvledTemperature, status.TempOfUVLED)
+		gauge(c.uvledTemperatureMax, attributes.TempOfUVLEDMax)
+		gauge(c.boxTemperature, status.TempOfBox)
+		gauge(c.boxTargetTemperature, status.TempTargetBox)
+		counter(c.printScreenSeconds, status.PrintScreen)
+		counter(c.releaseFilmUses, float64(status.ReleaseFilm))
+		gauge(c.releaseFilmMaxUses, float64(attributes.ReleaseFilmMax))
+		gauge(c.currentLayer, float64(status.PrintInfo.CurrentLayer))
+		gauge(c.totalLayers, float64(status.PrintInfo.TotalLayer))
+		gauge(c.elapsedSeconds, float64(status.PrintInfo.CurrentTicks)/1000)
+		gauge(c.estimatedSeconds, float64(status.PrintInfo.TotalTicks)/1000)
+		if status.PrintInfo.TotalLayer > 0 {
+			gauge(c.printProgressFraction, float64(status.PrintInfo.CurrentLayer)/float64(status.PrintInfo.TotalLayer))
+		}
+		gauge(c.printErrorNumber, float64(status.PrintInfo.ErrorNumber))
+		gauge(c.remainingMemoryBits, float64(attributes.RemainingMemory))
+		gauge(c.videoStreams, float64(attributes.NumberOfVideoStreamConnected))
+		gauge(c.videoStreamsMax, float64(attributes.MaximumVideoStreamAllowed))
+		gauge(c.timeLapseEnabled, boolean(status.TimeLapseStatus == sdcp.TimeLapseStatusOn))
+		gauge(c.usbDiskConnected, boolean(attributes.UsbDiskStatus == sdcp.UbsDiskStatusConnected))
+		gauge(c.cameraConnected, boolean(attributes.CameraStatus == sdcp.CameraStatusConnected))
+	}
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	namespace = "flux"
+)
+
+// Metrics exports the telemetry of every machine managed by SDCP, along with
+// the latency of API requests and SDCP requests, in the Prometheus format
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration     *prometheus.HistogramVec
+	sdcpRequestDuration *prometheus.HistogramVec
+}
+
+func New(s *sdcp.SDCP) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of API requests",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+		sdcpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "sdcp",
+			Name:      "request_duration_seconds",
+			Help:      "Round-trip duration of SDCP requests sent to machines",
+			Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"id", "command", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.sdcpRequestDuration,
+		newMachineCollector(s),
+	)
+
+	s.ObserveRequests(m.observe)
+
+	return m
+}
+
+// Handler returns a handler that serves the metrics
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware returns a middleware that records the duration of every API request
+func (m *Metrics) Middleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+		err := ctx.Next()
+
+		code := ctx.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			code = fiberErr.Code
+		} else if err != nil {
+			code = fiber.StatusInternalServerError
+		}
+
+		m.requestDuration.WithLabelValues(ctx.Method(), ctx.Route().Path, strconv.Itoa(code)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+func (m *Metrics) observe(machineID string, command sdcp.Command, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.sdcpRequestDuration.WithLabelValues(machineID, command.String(), result).Observe(duration.Seconds())
+}
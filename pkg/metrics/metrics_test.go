@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/registry"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+func TestMetrics(t *testing.T) {
+	const id = "0123456789abcdef"
+	path := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, registry.NewFile(path).Put(sdcp.Registration{ID: id, IP: "127.0.0.1"}))
+	s := sdcp.New(logging.Test(t, logging.Slog, t.Name()), registry.NewFile(path))
+	t.Cleanup(s.Close)
+	require.NoError(t, s.Restore())
+
+	m := New(s)
+	app := utils.DefaultFiberApp()
+	app.Use(m.Middleware())
+	app.Get("/metrics", m.Handler())
+	scrape := func() string {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	// A machine that has never been online only reports that it is not connected
+	body := scrape()
+	require.Regexp(t, `flux_machine_online\{id="`+id+`",model="",name="",state="(offline|connecting)"\} 0`, body)
+	require.NotContains(t, body, "flux_machine_uvled_temperature_celsius")
+
+	require.Contains(t, scrape(), `flux_api_request_duration_seconds_count{code="200",method="GET",route="/metrics"} 1`)
+}
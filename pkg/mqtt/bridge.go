@@ -0,0 +1,298 @@
+// Package mqtt bridges machines to an MQTT broker, publishing their status, attributes and print
+// lifecycle events, and accepting commands published to per-machine command topics.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	DefaultPrefix          = "flux"
+	DefaultDiscoveryPrefix = "homeassistant"
+	DefaultClientID        = "flux"
+
+	publishTimeout    = 10 * time.Second
+	disconnectQuiesce = 250 // milliseconds
+
+	qos = 1
+
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+)
+
+var (
+	ErrConnectFailed = errors.New("failed to connect to mqtt broker")
+)
+
+type Options struct {
+	Broker          string // URL of the broker, such as tcp://localhost:1883
+	ClientID        string
+	Username        string
+	Password        string
+	Prefix          string // Prefix of every topic published or subscribed to by the bridge
+	Discovery       bool   // Publish Home Assistant MQTT discovery payloads for every machine
+	DiscoveryPrefix string // Prefix Home Assistant subscribes to discovery payloads under
+}
+
+func (o *Options) defaults() {
+	if o.ClientID == "" {
+		o.ClientID = DefaultClientID
+	}
+	if o.Prefix == "" {
+		o.Prefix = DefaultPrefix
+	}
+	if o.DiscoveryPrefix == "" {
+		o.DiscoveryPrefix = DefaultDiscoveryPrefix
+	}
+}
+
+// client is the subset of paho.Client used by the bridge
+type client interface {
+	Connect() paho.Token
+	Disconnect(quiesce uint)
+	Publish(topic string, qos byte, retained bool, payload interface{}) paho.Token
+	Subscribe(topic string, qos byte, callback paho.MessageHandler) paho.Token
+}
+
+// Bridge publishes the state of every machine to an MQTT broker under a prefix:
+//
+//	{prefix}/bridge/state              online or offline, retained, set to offline by the broker if the bridge is lost
+//	{prefix}/{id}/availability         online or offline, retained
+//	{prefix}/{id}/status               the last status pushed by the machine, retained
+//	{prefix}/{id}/attributes           the last attributes pushed by the machine, retained
+//	{prefix}/{id}/event                print lifecycle, error and notice events
+//	{prefix}/{id}/command/{command}    commands sent to the machine, see Commands
+//
+// The result of every command is published to {prefix}/{id}/command/{command}/result. Retained commands
+// are ignored, as the broker would deliver them again every time the bridge reconnects.
+type Bridge struct {
+	logger  types.Logger
+	sdcp    *sdcp.SDCP
+	options Options
+	client  client
+
+	mu        sync.Mutex
+	announced map[string]bool // Machines whose discovery payloads have been published since the bridge connected
+
+	subscription *sdcp.Subscription
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+func New(s *sdcp.SDCP, options Options, logger types.Logger) *Bridge {
+	options.defaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bridge{
+		logger:    logger.SubLogger("mqtt"),
+		sdcp:      s,
+		options:   options,
+		announced: make(map[string]bool),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	o := paho.NewClientOptions().
+		AddBroker(options.Broker).
+		SetClientID(options.ClientID).
+		SetUsername(options.Username).
+		SetPassword(options.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetWill(b.topic("bridge", "state"), payloadOffline, qos, true).
+		SetOnConnectHandler(func(paho.Client) {
+			b.connected()
+		}).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			b.logger.Warn().Err(err).Msg("lost connection to mqtt broker, reconnecting")
+		})
+	b.client = paho.NewClient(o)
+
+	return b
+}
+
+// Start begins connecting to the broker and publishing the state of every machine. If the broker
+// cannot be reached, the bridge keeps trying to connect in the background.
+func (b *Bridge) Start() error {
+	b.subscription = b.sdcp.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for e := range b.subscription.C {
+			b.observe(e)
+		}
+	}()
+
+	t := b.client.Connect()
+	select {
+	case <-t.Done():
+		// Only fails immediately if the options are invalid, otherwise connection attempts are retried
+		if t.Error() != nil {
+			return errors.Join(ErrConnectFailed, t.Error())
+		}
+	default:
+	}
+	return nil
+}
+
+// Stop marks the bridge offline and disconnects from the broker
+func (b *Bridge) Stop() {
+	if b.subscription != nil {
+		b.subscription.Close()
+	}
+	b.mu.Lock()
+	b.cancel()
+	b.mu.Unlock()
+	b.wg.Wait()
+	b.publish(b.topic("bridge", "state"), true, payloadOffline)
+	b.client.Disconnect(disconnectQuiesce)
+}
+
+// connected subscribes to the command topics and republishes the state of every machine, since the
+// broker may have lost retained messages while the bridge was disconnected
+func (b *Bridge) connected() {
+	b.logger.Info().Str("broker", b.options.Broker).Msg("connected to mqtt broker")
+	b.publish(b.topic("bridge", "state"), true, payloadOnline)
+	t := b.client.Subscribe(b.topic("+", "command", "+"), qos, b.command)
+	if t.WaitTimeout(publishTimeout) && t.Error() != nil {
+		b.logger.Error().Err(t.Error()).Msg("failed to subscribe to command topics")
+	}
+
+	b.mu.Lock()
+	clear(b.announced)
+	b.mu.Unlock()
+	for _, m := range b.sdcp.Machines() {
+		b.announce(m)
+		b.publish(b.topic(m.ID(), "availability"), true, availability(m.State()))
+		b.publish(b.topic(m.ID(), "status"), true, m.Status())
+		b.publish(b.topic(m.ID(), "attributes"), true, m.Attributes())
+	}
+}
+
+func (b *Bridge) observe(e sdcp.Event) {
+	m, ok := b.sdcp.GetMachine(e.MachineID)
+	if !ok {
+		return
+	}
+	b.announce(m)
+	switch e.Type {
+	case sdcp.EventTypeStatus:
+		b.publish(b.topic(e.MachineID, "status"), true, e.Status)
+	case sdcp.EventTypeAttributes:
+		b.publish(b.topic(e.MachineID, "attributes"), true, e.Attributes)
+		if b.options.Discovery {
+			// The name or firmware of the machine may have changed
+			b.discover(m)
+		}
+	case sdcp.EventTypeState:
+		if e.State != sdcp.ConnectionStateConnecting {
+			b.publish(b.topic(e.MachineID, "availability"), true, availability(e.State))
+		}
+	case sdcp.EventTypePrint, sdcp.EventTypeError, sdcp.EventTypeNotice:
+		b.publish(b.topic(e.MachineID, "event"), false, newEvent(e))
+	}
+}
+
+// announce publishes the discovery payloads of a machine the first time it is seen
+func (b *Bridge) announce(m *sdcp.Machine) {
+	if !b.options.Discovery {
+		return
+	}
+	b.mu.Lock()
+	announced := b.announced[m.ID()]
+	b.announced[m.ID()] = true
+	b.mu.Unlock()
+	if !announced {
+		b.discover(m)
+	}
+}
+
+// publish publishes payload as is if it is a string, or encoded as JSON otherwise
+func (b *Bridge) publish(topic string, retained bool, payload any) {
+	var data []byte
+	switch p := payload.(type) {
+	case string:
+		data = []byte(p)
+	default:
+		var err error
+		data, err = json.Marshal(p)
+		if err != nil {
+			b.logger.Error().Err(err).Str("topic", topic).Msg("failed to encode mqtt payload")
+			return
+		}
+	}
+	t := b.client.Publish(topic, qos, retained, data)
+	if t.WaitTimeout(publishTimeout) && t.Error() != nil {
+		b.logger.Warn().Err(t.Error()).Str("topic", topic).Msg("failed to publish mqtt message")
+	}
+}
+
+func (b *Bridge) topic(parts ...string) string {
+	topic := b.options.Prefix
+	for _, p := range parts {
+		topic = fmt.Sprintf("%s/%s", topic, p)
+	}
+	return topic
+}
+
+// event is the payload of a print lifecycle, error or notice event
+type event struct {
+	Type    sdcp.EventType        `json:"type"`
+	Time    int64                 `json:"time"`
+	Print   *printEvent           `json:"print,omitempty"`
+	Error   sdcp.ErrorCode        `json:"error,omitempty"`
+	Notice  sdcp.NotificationType `json:"notice,omitempty"`
+	Message string                `json:"message,omitempty"`
+}
+
+type printEvent struct {
+	Type         sdcp.PrintEventType `json:"type"`
+	TaskID       string              `json:"task_id"`
+	Filename     string              `json:"filename"`
+	CurrentLayer int                 `json:"current_layer"`
+	TotalLayer   int                 `json:"total_layer"`
+	Error        sdcp.PrintInfoError `json:"error"`
+}
+
+func newEvent(e sdcp.Event) event {
+	ev := event{
+		Type: e.Type,
+		Time: e.Time.UnixMilli(),
+	}
+	switch {
+	case e.Print != nil:
+		ev.Print = &printEvent{
+			Type:         e.Print.Type,
+			TaskID:       e.Print.TaskID,
+			Filename:     e.Print.Filename,
+			CurrentLayer: e.Print.CurrentLayer,
+			TotalLayer:   e.Print.TotalLayer,
+			Error:        e.Print.Error,
+		}
+	case e.Error != nil:
+		ev.Error = e.Error.Data.ErrorCode
+		ev.Message = e.Error.Data.ErrorCode.String()
+	case e.Notice != nil:
+		ev.Notice = e.Notice.Data.Type
+		ev.Message = e.Notice.Data.Type.String()
+	}
+	return ev
+}
+
+func availability(state sdcp.ConnectionState) string {
+	if state == sdcp.ConnectionStateOnline {
+		return payloadOnline
+	}
+	return payloadOffline
+}
@@ -0,0 +1,183 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/sdcp/sdcptest"
+)
+
+type token struct {
+	done chan struct{}
+}
+
+func newToken() *token {
+	t := &token{done: make(chan struct{})}
+	close(t.done)
+	return t
+}
+
+func (t *token) Wait() bool                     { return true }
+func (t *token) WaitTimeout(time.Duration) bool { return true }
+func (t *token) Done() <-chan struct{}          { return t.done }
+func (t *token) Error() error                   { return nil }
+
+type message struct {
+	topic    string
+	payload  []byte
+	retained bool
+}
+
+func (m *message) Duplicate() bool   { return false }
+func (m *message) Qos() byte         { return qos }
+func (m *message) Retained() bool    { return m.retained }
+func (m *message) Topic() string     { return m.topic }
+func (m *message) MessageID() uint16 { return 0 }
+func (m *message) Payload() []byte   { return m.payload }
+func (m *message) Ack()              {}
+
+// broker stands in for the client of a real broker, recording every published message
+type broker struct {
+	mu        sync.Mutex
+	connected func()
+	handler   paho.MessageHandler
+	messages  []message
+}
+
+func (b *broker) Connect() paho.Token {
+	go b.connected()
+	return newToken()
+}
+
+func (b *broker) Disconnect(uint) {}
+
+func (b *broker) Publish(topic string, _ byte, retained bool, payload interface{}) paho.Token {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, message{topic: topic, payload: payload.([]byte), retained: retained})
+	return newToken()
+}
+
+func (b *broker) Subscribe(_ string, _ byte, callback paho.MessageHandler) paho.Token {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handler = callback
+	return newToken()
+}
+
+func (b *broker) send(topic string, payload string) {
+	b.deliver(message{topic: topic, payload: []byte(payload)})
+}
+
+func (b *broker) deliver(m message) {
+	b.mu.Lock()
+	handler := b.handler
+	b.mu.Unlock()
+	handler(nil, &m)
+}
+
+// last returns the last message published to topic
+func (b *broker) last(topic string) (message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := len(b.messages) - 1; i >= 0; i-- {
+		if b.messages[i].topic == topic {
+			return b.messages[i], true
+		}
+	}
+	return message{}, false
+}
+
+func TestBridge(t *testing.T) {
+	logger := logging.Test(t, logging.Slog, t.Name())
+	s := sdcp.New(logger, nil)
+	t.Cleanup(s.Close)
+
+	printer, err := sdcptest.NewPrinter(sdcptest.Config{LayerTime: time.Second})
+	require.NoError(t, err)
+	t.Cleanup(printer.Close)
+	printer.AddFile(sdcp.LocalPath("model.ctb"), 1024, 10)
+	require.NoError(t, s.Register(printer.Registration()))
+	id := printer.ID()
+
+	b := New(s, Options{Broker: "tcp://localhost:1883", Prefix: "printers", Discovery: true}, logger)
+	fake := &broker{connected: b.connected}
+	b.client = fake
+	require.NoError(t, b.Start())
+
+	waitFor := func(topic string, check func(m message) bool) message {
+		var m message
+		require.Eventually(t, func() bool {
+			var ok bool
+			m, ok = fake.last(topic)
+			return ok && check(m)
+		}, 10*time.Second, 10*time.Millisecond)
+		return m
+	}
+	payload := func(value string) func(m message) bool {
+		return func(m message) bool {
+			return string(m.payload) == value
+		}
+	}
+
+	require.True(t, waitFor("printers/bridge/state", payload(payloadOnline)).retained)
+	require.True(t, waitFor("printers/"+id+"/availability", payload(payloadOnline)).retained)
+	attributes := waitFor("printers/"+id+"/attributes", func(m message) bool {
+		var a sdcp.Attributes
+		require.NoError(t, json.Unmarshal(m.payload, &a))
+		return a.MainboardID == id
+	})
+	require.True(t, attributes.retained)
+
+	discovery := waitFor("homeassistant/sensor/flux_"+id+"/progress/config", func(message) bool {
+		return true
+	})
+	require.True(t, discovery.retained)
+	var e entity
+	require.NoError(t, json.Unmarshal(discovery.payload, &e))
+	require.Equal(t, "flux_"+id+"_progress", e.UniqueID)
+	require.Equal(t, "printers/"+id+"/status", e.StateTopic)
+	require.Equal(t, []string{"flux_" + id}, e.Device.Identifiers)
+	require.Len(t, e.Availability, 2)
+	_, ok := fake.last("homeassistant/button/flux_" + id + "/stop/config")
+	require.True(t, ok)
+
+	fake.send("printers/"+id+"/command/start_print", `{"filename": "model.ctb"}`)
+	waitFor("printers/"+id+"/command/start_print/result", payload(`{"success":true}`))
+	waitFor("printers/"+id+"/event", func(m message) bool {
+		var ev event
+		require.NoError(t, json.Unmarshal(m.payload, &ev))
+		return ev.Print != nil && ev.Print.Type == sdcp.PrintEventTypeStarted
+	})
+	waitFor("printers/"+id+"/status", func(m message) bool {
+		var status sdcp.Status
+		require.NoError(t, json.Unmarshal(m.payload, &status))
+		return status.PrintInfo.Filename == string(sdcp.LocalPath("model.ctb"))
+	})
+
+	fake.send("printers/"+id+"/command/stop_print", "")
+	waitFor("printers/"+id+"/command/stop_print/result", payload(`{"success":true}`))
+	fake.send("printers/"+id+"/command/unknown", "")
+	waitFor("printers/"+id+"/command/unknown/result", payload(`{"success":false,"error":"unknown command"}`))
+	fake.send("printers/"+id+"/command/video_stream", "maybe")
+	waitFor("printers/"+id+"/command/video_stream/result", func(m message) bool {
+		var r result
+		require.NoError(t, json.Unmarshal(m.payload, &r))
+		return !r.Success
+	})
+
+	// Retained commands would run again every time the bridge reconnects
+	fake.deliver(message{topic: "printers/" + id + "/command/skip_preheating", retained: true})
+
+	b.Stop()
+	require.True(t, waitFor("printers/bridge/state", payload(payloadOffline)).retained)
+	_, ok = fake.last("printers/" + id + "/command/skip_preheating/result")
+	require.False(t, ok)
+}
@@ -0,0 +1,157 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	commandTimeout = 30 * time.Second
+)
+
+var (
+	ErrUnknownMachine = errors.New("unknown machine")
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrInvalidPayload = errors.New("invalid command payload")
+)
+
+type Command string
+
+const (
+	CommandStartPrint          Command = "start_print"           // Payload is the filename, or {"filename": "...", "start_layer": 0}
+	CommandPausePrint          Command = "pause_print"           // Payload is ignored
+	CommandResumePrint         Command = "resume_print"          // Payload is ignored
+	CommandStopPrint           Command = "stop_print"            // Payload is ignored
+	CommandStopFeedingMaterial Command = "stop_feeding_material" // Payload is ignored
+	CommandSkipPreheating      Command = "skip_preheating"       // Payload is ignored
+	CommandStatusRefresh       Command = "status_refresh"        // Payload is ignored
+	CommandAttributesRefresh   Command = "attributes_refresh"    // Payload is ignored
+	CommandVideoStream         Command = "video_stream"          // Payload is on or off
+)
+
+// Commands lists every command accepted on the command topics of a machine
+var Commands = []Command{
+	CommandStartPrint,
+	CommandPausePrint,
+	CommandResumePrint,
+	CommandStopPrint,
+	CommandStopFeedingMaterial,
+	CommandSkipPreheating,
+	CommandStatusRefresh,
+	CommandAttributesRefresh,
+	CommandVideoStream,
+}
+
+type startPrintPayload struct {
+	Filename   string `json:"filename"`
+	StartLayer int    `json:"start_layer"`
+}
+
+// result is published once a command has been sent to the machine
+type result struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// command handles a message published to a command topic. Commands are run in their own goroutine,
+// since the client does not deliver further messages until the handler returns.
+func (b *Bridge) command(_ paho.Client, message paho.Message) {
+	if message.Retained() {
+		b.logger.Warn().Str("topic", message.Topic()).Msg("ignoring retained mqtt command")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(message.Topic(), b.options.Prefix+"/"), "/")
+	if len(parts) != 3 || parts[1] != "command" {
+		return
+	}
+	machineID, command := parts[0], Command(parts[2])
+	payload := message.Payload()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ctx.Err() != nil {
+		return
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		err := b.run(machineID, command, payload)
+		res := result{Success: err == nil}
+		if err != nil {
+			res.Error = err.Error()
+			b.logger.Warn().Err(err).Str("machine", machineID).Msgf("mqtt command %s failed", command)
+		}
+		b.publish(b.topic(machineID, "command", string(command), "result"), false, res)
+	}()
+}
+
+func (b *Bridge) run(machineID string, command Command, payload []byte) error {
+	m, ok := b.sdcp.GetMachine(machineID)
+	if !ok {
+		return ErrUnknownMachine
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, commandTimeout)
+	defer cancel()
+
+	var err error
+	switch command {
+	case CommandStartPrint:
+		p := startPrintPayload{Filename: strings.TrimSpace(string(payload))}
+		if strings.HasPrefix(p.Filename, "{") {
+			err = json.Unmarshal(payload, &p)
+			if err != nil {
+				return errors.Join(ErrInvalidPayload, err)
+			}
+		}
+		if p.Filename == "" {
+			return ErrInvalidPayload
+		}
+		_, err = m.StartPrint(ctx, p.Filename, p.StartLayer)
+	case CommandPausePrint:
+		_, err = m.PausePrint(ctx)
+	case CommandResumePrint:
+		_, err = m.ResumePrint(ctx)
+	case CommandStopPrint:
+		_, err = m.StopPrint(ctx)
+	case CommandStopFeedingMaterial:
+		_, err = m.StopFeedingMaterial(ctx)
+	case CommandSkipPreheating:
+		_, err = m.SkipPreheating(ctx)
+	case CommandStatusRefresh:
+		_, err = m.StatusRefresh(ctx)
+	case CommandAttributesRefresh:
+		_, err = m.AttributesRefresh(ctx)
+	case CommandVideoStream:
+		var enable bool
+		enable, err = parseSwitch(payload)
+		if err != nil {
+			return err
+		}
+		_, err = m.EnableDisableVideo(ctx, enable)
+	default:
+		return ErrUnknownCommand
+	}
+	return err
+}
+
+// parseSwitch parses the payload of a command that turns something on or off
+func parseSwitch(payload []byte) (bool, error) {
+	switch v := strings.ToLower(strings.TrimSpace(string(payload))); v {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	default:
+		enable, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, errors.Join(ErrInvalidPayload, err)
+		}
+		return enable, nil
+	}
+}
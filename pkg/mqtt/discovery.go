@@ -0,0 +1,130 @@
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// device identifies the machine every discovered entity belongs to in Home Assistant
+type device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+type availabilityTopic struct {
+	Topic string `json:"topic"`
+}
+
+// entity is a Home Assistant MQTT discovery payload
+type entity struct {
+	Name              string              `json:"name"`
+	UniqueID          string              `json:"unique_id"`
+	ObjectID          string              `json:"object_id"`
+	Device            device              `json:"device"`
+	Availability      []availabilityTopic `json:"availability"`
+	AvailabilityMode  string              `json:"availability_mode"`
+	StateTopic        string              `json:"state_topic,omitempty"`
+	ValueTemplate     string              `json:"value_template,omitempty"`
+	UnitOfMeasurement string              `json:"unit_of_measurement,omitempty"`
+	DeviceClass       string              `json:"device_class,omitempty"`
+	StateClass        string              `json:"state_class,omitempty"`
+	Icon              string              `json:"icon,omitempty"`
+	CommandTopic      string              `json:"command_topic,omitempty"`
+	PayloadPress      string              `json:"payload_press,omitempty"`
+}
+
+type component struct {
+	kind   string // Home Assistant platform of the entity, such as sensor or button
+	object string
+	entity entity
+}
+
+// discover publishes the retained Home Assistant discovery payloads of every entity of a machine
+func (b *Bridge) discover(m *sdcp.Machine) {
+	attributes := m.Attributes()
+	name := m.Label()
+	if name == "" {
+		name = attributes.MachineName
+	}
+	if name == "" {
+		name = m.ID()
+	}
+	d := device{
+		Identifiers:  []string{"flux_" + m.ID()},
+		Name:         name,
+		Manufacturer: attributes.BrandName,
+		Model:        attributes.MachineModel,
+		SWVersion:    attributes.FirmwareVersion,
+	}
+	status := b.topic(m.ID(), "status")
+
+	components := []component{
+		{kind: "sensor", object: "machine_status", entity: entity{Name: "Status", StateTopic: status, ValueTemplate: names("value_json.CurrentStatus[0]", machineStatuses), Icon: "mdi:printer-3d"}},
+		{kind: "sensor", object: "print_status", entity: entity{Name: "Print status", StateTopic: status, ValueTemplate: names("value_json.PrintInfo.Status", printInfoStatuses), Icon: "mdi:printer-3d-nozzle"}},
+		{kind: "sensor", object: "filename", entity: entity{Name: "File", StateTopic: status, ValueTemplate: "{{ value_json.PrintInfo.Filename }}", Icon: "mdi:file"}},
+		{kind: "sensor", object: "progress", entity: entity{Name: "Progress", StateTopic: status, ValueTemplate: "{{ (100 * value_json.PrintInfo.CurrentLayer / value_json.PrintInfo.TotalLayer) | round(1) if value_json.PrintInfo.TotalLayer > 0 else 0 }}", UnitOfMeasurement: "%", StateClass: "measurement", Icon: "mdi:progress-clock"}},
+		{kind: "sensor", object: "current_layer", entity: entity{Name: "Current layer", StateTopic: status, ValueTemplate: "{{ value_json.PrintInfo.CurrentLayer }}", StateClass: "measurement", Icon: "mdi:layers"}},
+		{kind: "sensor", object: "total_layers", entity: entity{Name: "Total layers", StateTopic: status, ValueTemplate: "{{ value_json.PrintInfo.TotalLayer }}", Icon: "mdi:layers-triple"}},
+		{kind: "sensor", object: "remaining_time", entity: entity{Name: "Remaining time", StateTopic: status, ValueTemplate: "{{ ((value_json.PrintInfo.TotalTicks - value_json.PrintInfo.CurrentTicks) / 1000) | int if value_json.PrintInfo.TotalTicks > value_json.PrintInfo.CurrentTicks else 0 }}", UnitOfMeasurement: "s", DeviceClass: "duration"}},
+		{kind: "sensor", object: "uv_led_temperature", entity: entity{Name: "UV LED temperature", StateTopic: status, ValueTemplate: "{{ value_json.TempOfUVLED }}", UnitOfMeasurement: "°C", DeviceClass: "temperature", StateClass: "measurement"}},
+		{kind: "sensor", object: "enclosure_temperature", entity: entity{Name: "Enclosure temperature", StateTopic: status, ValueTemplate: "{{ value_json.TempOfBox }}", UnitOfMeasurement: "°C", DeviceClass: "temperature", StateClass: "measurement"}},
+		{kind: "button", object: "pause", entity: entity{Name: "Pause print", CommandTopic: b.topic(m.ID(), "command", string(CommandPausePrint)), Icon: "mdi:pause"}},
+		{kind: "button", object: "resume", entity: entity{Name: "Resume print", CommandTopic: b.topic(m.ID(), "command", string(CommandResumePrint)), Icon: "mdi:play"}},
+		{kind: "button", object: "stop", entity: entity{Name: "Stop print", CommandTopic: b.topic(m.ID(), "command", string(CommandStopPrint)), Icon: "mdi:stop"}},
+	}
+
+	for _, c := range components {
+		e := c.entity
+		e.UniqueID = fmt.Sprintf("flux_%s_%s", m.ID(), c.object)
+		e.ObjectID = e.UniqueID
+		e.Device = d
+		e.Availability = []availabilityTopic{
+			{Topic: b.topic("bridge", "state")},
+			{Topic: b.topic(m.ID(), "availability")},
+		}
+		e.AvailabilityMode = "all"
+		if e.CommandTopic != "" {
+			e.PayloadPress = "{}"
+		}
+		b.publish(fmt.Sprintf("%s/%s/flux_%s/%s/config", b.options.DiscoveryPrefix, c.kind, m.ID(), c.object), true, e)
+	}
+}
+
+var machineStatuses = []sdcp.MachineStatus{
+	sdcp.MachineStatusIdle,
+	sdcp.MachineStatusPrinting,
+	sdcp.MachineStatusFileTransferring,
+	sdcp.MachineStatusExposureTesting,
+	sdcp.MachineStatusDevicesTesting,
+}
+
+var printInfoStatuses = []sdcp.PrintInfoStatus{
+	sdcp.PrintInfoStatusIdle,
+	sdcp.PrintInfoStatusHoming,
+	sdcp.PrintInfoStatusDropping,
+	sdcp.PrintInfoStatusExposing,
+	sdcp.PrintInfoStatusLifting,
+	sdcp.PrintInfoStatusPausing,
+	sdcp.PrintInfoStatusPaused,
+	sdcp.PrintInfoStatusStopping,
+	sdcp.PrintInfoStatusStopped,
+	sdcp.PrintInfoStatusComplete,
+	sdcp.PrintInfoStatusFileChecking,
+}
+
+// names returns a template that maps the integer status at value to its name
+func names[T interface {
+	~int
+	fmt.Stringer
+}](value string, statuses []T) string {
+	entries := make([]string, len(statuses))
+	for i, s := range statuses {
+		entries[i] = fmt.Sprintf("%d: '%s'", int(s), s)
+	}
+	return fmt.Sprintf("{{ {%s}.get(%s, 'unknown') }}", strings.Join(entries, ", "), value)
+}
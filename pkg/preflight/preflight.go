@@ -0,0 +1,218 @@
+// Package preflight checks whether a sliced file can be uploaded to and printed by a machine,
+// so that problems are reported before the machine rejects the file or fails the print.
+package preflight
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/slicefile"
+)
+
+const (
+	// sizeTolerance is the difference in millimeters between the display size of a file and a machine that is ignored
+	sizeTolerance = 1.0
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The file will be rejected by the machine, or the print will fail
+	SeverityWarning Severity = "warning" // The file may not print as expected
+)
+
+type Code string
+
+const (
+	CodeMachineOffline      Code = "machine_offline"
+	CodeMachineBusy         Code = "machine_busy"
+	CodeUnknownAttributes   Code = "unknown_attributes"
+	CodeUnknownFormat       Code = "unknown_format"
+	CodeUnsupportedFileType Code = "unsupported_file_type"
+	CodeResolutionMismatch  Code = "resolution_mismatch"
+	CodeModelMismatch       Code = "model_mismatch"
+	CodeDisplayMismatch     Code = "display_mismatch"
+	CodeHeightExceeded      Code = "height_exceeded"
+	CodeInsufficientStorage Code = "insufficient_storage"
+	CodeUsbDiskDisconnected Code = "usb_disk_disconnected"
+	CodeDeviceFault         Code = "device_fault"
+)
+
+// Problem is a reason a file may not print on a machine
+type Problem struct {
+	Code     Code     `json:"code"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Report lists every problem found by a check
+type Report struct {
+	Problems []Problem `json:"problems"`
+}
+
+// Passed returns true if no problem prevents the file from being printed
+func (r *Report) Passed() bool {
+	return !slices.ContainsFunc(r.Problems, func(p Problem) bool {
+		return p.Severity == SeverityError
+	})
+}
+
+func (r *Report) add(code Code, severity Severity, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// File is the sliced file being checked. Metadata is nil if the header of the file could not be parsed.
+type File struct {
+	Name     string
+	Size     int64
+	Metadata *slicefile.Metadata
+}
+
+// NewFile parses the header of the file of the given size read from r. Files that cannot be parsed
+// are still checked, but only against the file type of their extension.
+func NewFile(name string, r io.ReaderAt, size int64) File {
+	f := File{
+		Name: name,
+		Size: size,
+	}
+	metadata, err := slicefile.Parse(r, size)
+	if err == nil {
+		f.Metadata = metadata
+	}
+	return f
+}
+
+func (f *File) fileType() sdcp.SupportedFileType {
+	if f.Metadata != nil {
+		return f.Metadata.FileType
+	}
+	return sdcp.SupportedFileType(strings.ToUpper(strings.TrimPrefix(filepath.Ext(f.Name), ".")))
+}
+
+// CheckMachine checks whether the file can be uploaded to and printed by the machine right now
+func CheckMachine(m *sdcp.Machine, f File) *Report {
+	r := Check(m.Attributes(), f)
+	if m.State() != sdcp.ConnectionStateOnline {
+		r.add(CodeMachineOffline, SeverityError, "machine is %s", m.State())
+	}
+	if t, ok := m.Transfer(); ok {
+		r.add(CodeMachineBusy, SeverityError, "machine is receiving %s", t.Filename)
+	}
+	status := m.Status()
+	if slices.ContainsFunc(status.CurrentStatus, func(s sdcp.MachineStatus) bool {
+		return s != sdcp.MachineStatusIdle
+	}) {
+		r.add(CodeMachineBusy, SeverityError, "machine is not idle")
+	}
+	return r
+}
+
+// Check checks whether the file is compatible with a machine with the given attributes
+func Check(attributes *sdcp.Attributes, f File) *Report {
+	r := new(Report)
+	if attributes == nil || attributes.MainboardID == "" {
+		r.add(CodeUnknownAttributes, SeverityWarning, "machine attributes are unknown")
+		return r
+	}
+
+	fileType := f.fileType()
+	if len(attributes.SupportFileType) > 0 && !slices.Contains(attributes.SupportFileType, fileType) {
+		r.add(CodeUnsupportedFileType, SeverityError, "machine does not support %q files", fileType)
+	}
+
+	if f.Metadata == nil {
+		r.add(CodeUnknownFormat, SeverityWarning, "file header could not be read, so its settings were not checked")
+	} else {
+		checkMetadata(r, attributes, f.Metadata)
+	}
+
+	if f.Size*8 > int64(attributes.RemainingMemory) {
+		r.add(CodeInsufficientStorage, SeverityError, "file needs %d bytes but the machine has %d bytes free", f.Size, attributes.RemainingMemory/8)
+	}
+	if attributes.UsbDiskStatus != sdcp.UbsDiskStatusConnected {
+		r.add(CodeUsbDiskDisconnected, SeverityWarning, "no USB drive is connected, so the file can only be stored locally")
+	}
+	checkDevices(r, attributes.DevicesStatus)
+
+	return r
+}
+
+func checkMetadata(r *Report, attributes *sdcp.Attributes, m *slicefile.Metadata) {
+	if attributes.Resolution != "" && !strings.EqualFold(strings.ReplaceAll(attributes.Resolution, " ", ""), m.Resolution()) {
+		r.add(CodeResolutionMismatch, SeverityError, "file resolution %s does not match machine resolution %s", m.Resolution(), attributes.Resolution)
+	}
+
+	// Slicers do not name machines exactly as the machines name themselves, so mismatches are only warnings
+	if m.MachineName != "" && attributes.MachineModel != "" {
+		file, machine := strings.ToLower(m.MachineName), strings.ToLower(attributes.MachineModel)
+		if !strings.Contains(file, machine) && !strings.Contains(machine, file) {
+			r.add(CodeModelMismatch, SeverityWarning, "file was sliced for %q but the machine is a %q", m.MachineName, attributes.MachineModel)
+		}
+	}
+
+	x, y, z, ok := size(attributes.XYZsize)
+	if !ok {
+		return
+	}
+	if m.Height > z {
+		r.add(CodeHeightExceeded, SeverityError, "model is %.2fmm tall but the machine can print at most %.2fmm", m.Height, z)
+	}
+	if m.SizeX > 0 && m.SizeY > 0 && (math.Abs(m.SizeX-x) > sizeTolerance || math.Abs(m.SizeY-y) > sizeTolerance) {
+		r.add(CodeDisplayMismatch, SeverityWarning, "file display size %.2fx%.2fmm does not match machine display size %.2fx%.2fmm", m.SizeX, m.SizeY, x, y)
+	}
+}
+
+func checkDevices(r *Report, d sdcp.DeviceStatus) {
+	if d.TempSensorStatusOfUVLED != sdcp.TempSensorStatusOfUVLEDNormal {
+		r.add(CodeDeviceFault, SeverityError, "UV LED temperature sensor is not working")
+	}
+	if d.LCDStatus != sdcp.LCDStatusConnected {
+		r.add(CodeDeviceFault, SeverityError, "exposure screen is not connected")
+	}
+	switch d.SgStatus {
+	case sdcp.SgStatusDisconnected:
+		r.add(CodeDeviceFault, SeverityError, "strain gauge is not connected")
+	case sdcp.SgStatusCalibrationFailed:
+		r.add(CodeDeviceFault, SeverityError, "strain gauge calibration failed")
+	}
+	if d.ZMotorStatus != sdcp.ZMotorStatusConnected {
+		r.add(CodeDeviceFault, SeverityError, "Z-axis motor is not connected")
+	}
+	// Not every machine has a rotary or X-axis motor, so their status is only a warning
+	if d.RotateMotorStatus != sdcp.RotateMotorStatusConnected {
+		r.add(CodeDeviceFault, SeverityWarning, "rotary axis motor is not connected")
+	}
+	if d.XMotorStatus != sdcp.XMotorStatusConnected {
+		r.add(CodeDeviceFault, SeverityWarning, "X-axis motor is not connected")
+	}
+	if d.ReleaseFilmState != sdcp.ReleaseFilmStateNormal {
+		r.add(CodeDeviceFault, SeverityWarning, "release film is abnormal")
+	}
+}
+
+// size parses the XYZsize attribute of a machine, such as "218.88x122.88x260"
+func size(xyz string) (float64, float64, float64, bool) {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(xyz, " ", "")), "x")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+	var v [3]float64
+	for i, part := range parts {
+		var err error
+		v[i], err = strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+	}
+	return v[0], v[1], v[2], true
+}
@@ -0,0 +1,81 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/slicefile"
+)
+
+func codes(r *Report, severity Severity) []Code {
+	var res []Code
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			res = append(res, p.Code)
+		}
+	}
+	return res
+}
+
+func TestCheck(t *testing.T) {
+	attributes := &sdcp.Attributes{
+		MachineModel:    "ELEGOO Saturn 4 Ultra",
+		Resolution:      "11520x5120",
+		XYZsize:         "218.88x122.88x220",
+		MainboardID:     "0123456789abcdef",
+		UsbDiskStatus:   sdcp.UbsDiskStatusConnected,
+		SupportFileType: []sdcp.SupportedFileType{sdcp.SupportedFileTypeCTB, sdcp.SupportedFileTypeGOO},
+		DevicesStatus: sdcp.DeviceStatus{
+			TempSensorStatusOfUVLED: sdcp.TempSensorStatusOfUVLEDNormal,
+			LCDStatus:               sdcp.LCDStatusConnected,
+			SgStatus:                sdcp.SgStatusNormal,
+			ZMotorStatus:            sdcp.ZMotorStatusConnected,
+			RotateMotorStatus:       sdcp.RotateMotorStatusConnected,
+			ReleaseFilmState:        sdcp.ReleaseFilmStateNormal,
+			XMotorStatus:            sdcp.XMotorStatusConnected,
+		},
+		RemainingMemory: 1024 * 8,
+	}
+	metadata := &slicefile.Metadata{
+		FileType:    sdcp.SupportedFileTypeCTB,
+		MachineName: "Saturn 4 Ultra",
+		ResolutionX: 11520,
+		ResolutionY: 5120,
+		SizeX:       218.88,
+		SizeY:       122.88,
+		Height:      50,
+	}
+
+	r := Check(attributes, File{Name: "model.ctb", Size: 512, Metadata: metadata})
+	require.True(t, r.Passed())
+	require.Empty(t, r.Problems)
+
+	mismatched := *metadata
+	mismatched.ResolutionX, mismatched.ResolutionY = 7680, 4320
+	mismatched.MachineName = "Mars 4"
+	mismatched.SizeX = 153.36
+	mismatched.Height = 240
+	r = Check(attributes, File{Name: "model.ctb", Size: 2048, Metadata: &mismatched})
+	require.False(t, r.Passed())
+	require.Equal(t, []Code{CodeResolutionMismatch, CodeHeightExceeded, CodeInsufficientStorage}, codes(r, SeverityError))
+	require.Equal(t, []Code{CodeModelMismatch, CodeDisplayMismatch}, codes(r, SeverityWarning))
+
+	faulty := *attributes
+	faulty.UsbDiskStatus = sdcp.UsbDiskStatusDisconnected
+	faulty.DevicesStatus.SgStatus = sdcp.SgStatusCalibrationFailed
+	faulty.DevicesStatus.ReleaseFilmState = sdcp.ReleaseFilmStateAbnormal
+	r = Check(&faulty, File{Name: "model.stl", Size: 512})
+	require.Equal(t, []Code{CodeUnsupportedFileType, CodeDeviceFault}, codes(r, SeverityError))
+	require.Equal(t, []Code{CodeUnknownFormat, CodeUsbDiskDisconnected, CodeDeviceFault}, codes(r, SeverityWarning))
+
+	full := *attributes
+	full.RemainingMemory = 0
+	r = Check(&full, File{Name: "model.ctb", Size: 512, Metadata: metadata})
+	require.Equal(t, []Code{CodeInsufficientStorage}, codes(r, SeverityError))
+
+	r = Check(&sdcp.Attributes{}, File{Name: "model.ctb", Size: 512, Metadata: metadata})
+	require.True(t, r.Passed())
+	require.Equal(t, []Code{CodeUnknownAttributes}, codes(r, SeverityWarning))
+}
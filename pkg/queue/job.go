@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+type State string
+
+const (
+	StateQueued    State = "queued"    // Waiting for a compatible machine to become idle
+	StateHeld      State = "held"      // Will not be scheduled until it is released
+	StateUploading State = "uploading" // Being uploaded to the machine it was scheduled on
+	StatePrinting  State = "printing"  // Being printed by the machine it was scheduled on
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Active returns true if the job is assigned to a machine
+func (s State) Active() bool {
+	return s == StateUploading || s == StatePrinting
+}
+
+// Finished returns true if the job will not be scheduled again unless it is retried
+func (s State) Finished() bool {
+	return s == StateCompleted || s == StateFailed || s == StateCancelled
+}
+
+// Constraints restrict the machines a job can be scheduled on, empty constraints match every machine
+type Constraints struct {
+	Model      string                 `json:"model,omitempty"`      // Must match Attributes.MachineModel
+	Resolution string                 `json:"resolution,omitempty"` // Must match Attributes.Resolution
+	FileType   sdcp.SupportedFileType `json:"file_type,omitempty"`  // Must be in Attributes.SupportFileType, defaults to the file extension
+	Machines   []string               `json:"machines,omitempty"`   // IDs of the machines the job may be scheduled on
+	Tags       []string               `json:"tags,omitempty"`       // Tags every machine the job is scheduled on must have
+}
+
+// Job is a sliced file waiting to be printed, or being printed, by one of the registered machines
+type Job struct {
+	ID          string      `json:"id"`
+	Filename    string      `json:"filename"`
+	Size        int64       `json:"size"`
+	Priority    int         `json:"priority"`
+	Constraints Constraints `json:"constraints"`
+	State       State       `json:"state"`
+	MachineID   string      `json:"machine_id,omitempty"`
+	TaskID      string      `json:"task_id,omitempty"` // Set once the machine reports that it is printing the job
+	Attempts    int         `json:"attempts"`
+	MaxAttempts int         `json:"max_attempts"`
+	Error       string      `json:"error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	StartedAt   time.Time   `json:"started_at,omitempty"`
+	FinishedAt  time.Time   `json:"finished_at,omitempty"`
+}
+
+// fileType returns the file type the job requires
+func (j *Job) fileType() sdcp.SupportedFileType {
+	if j.Constraints.FileType != "" {
+		return j.Constraints.FileType
+	}
+	return sdcp.SupportedFileType(strings.ToUpper(strings.TrimPrefix(filepath.Ext(j.Filename), ".")))
+}
+
+// Compatible returns true if the job can be printed by the machine
+func (j *Job) Compatible(m *sdcp.Machine) bool {
+	attributes := m.Attributes()
+	c := j.Constraints
+	if len(c.Machines) > 0 && !slices.Contains(c.Machines, m.ID()) {
+		return false
+	}
+	tags := m.Tags()
+	for _, tag := range c.Tags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	if c.Model != "" && !strings.EqualFold(c.Model, attributes.MachineModel) {
+		return false
+	}
+	if c.Resolution != "" && c.Resolution != attributes.Resolution {
+		return false
+	}
+	if t := j.fileType(); t != "" && len(attributes.SupportFileType) > 0 && !slices.Contains(attributes.SupportFileType, t) {
+		return false
+	}
+	return true
+}
+
+// compare orders jobs by descending priority, then by submission time
+func compare(a *Job, b *Job) int {
+	if a.Priority != b.Priority {
+		return b.Priority - a.Priority
+	}
+	return a.CreatedAt.Compare(b.CreatedAt)
+}
+
+// idle returns true if the machine is online and is not printing or transferring a file
+func idle(m *sdcp.Machine) bool {
+	if m.State() != sdcp.ConnectionStateOnline {
+		return false
+	}
+	if _, ok := m.Transfer(); ok {
+		return false
+	}
+	status := m.Status()
+	return len(status.CurrentStatus) > 0 && !slices.ContainsFunc(status.CurrentStatus, func(s sdcp.MachineStatus) bool {
+		return s != sdcp.MachineStatusIdle
+	})
+}
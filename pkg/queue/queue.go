@@ -0,0 +1,399 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/slicefile"
+)
+
+var (
+	ErrReadFailed       = errors.New("failed to read queue")
+	ErrWriteFailed      = errors.New("failed to write queue")
+	ErrSubmitFailed     = errors.New("failed to submit job")
+	ErrJobNotFound      = errors.New("job not found")
+	ErrInvalidFile      = errors.New("invalid file")
+	ErrInvalidState     = errors.New("job is not in a valid state for this operation")
+	ErrCancelFailed     = errors.New("failed to cancel job")
+	ErrMaximumAttempts  = errors.New("maximum number of attempts reached")
+	ErrPrintNotStarted  = errors.New("machine did not start printing")
+	ErrPrintStopped     = errors.New("print was stopped")
+	ErrMachineNotFound  = errors.New("machine is no longer registered")
+	ErrTaskNotFound     = errors.New("print task not found in machine history")
+	ErrTaskFailed       = errors.New("print task failed")
+	ErrUnknownTaskState = errors.New("unknown print task state")
+	ErrNoThumbnail      = errors.New("job file has no thumbnail")
+)
+
+const (
+	DefaultMaxAttempts = 3
+
+	jobsFile         = "jobs.json"
+	filesDirectory   = "files"
+	scheduleInterval = 5 * time.Second
+	startTimeout     = 2 * time.Minute
+	requestTimeout   = 30 * time.Second
+)
+
+// SubmitOptions configure how a job is scheduled
+type SubmitOptions struct {
+	Priority    int // Jobs with a higher priority are scheduled first
+	Constraints Constraints
+	MaxAttempts int  // Number of times the job is attempted before it fails, defaults to DefaultMaxAttempts
+	Hold        bool // Submits the job held, so that it is not scheduled until it is released
+}
+
+// Queue schedules submitted jobs onto idle compatible machines, uploading and starting each
+// job and tracking it until it completes. Jobs and their files are persisted in a local directory.
+type Queue struct {
+	logger types.Logger
+	sdcp   *sdcp.SDCP
+	dir    string
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+
+	// cancels stops the upload of jobs that are being uploaded
+	cancels map[string]func()
+	// resolving holds the jobs whose print task is being looked up in the machine history
+	resolving map[string]struct{}
+
+	kick         chan struct{}
+	subscription *sdcp.Subscription
+	ctx          context.Context
+	cancel       func()
+	wg           sync.WaitGroup
+}
+
+func New(s *sdcp.SDCP, dir string, logger types.Logger) (*Queue, error) {
+	q := &Queue{
+		logger:    logger.SubLogger("queue"),
+		sdcp:      s,
+		dir:       dir,
+		jobs:      make(map[string]*Job),
+		cancels:   make(map[string]func()),
+		resolving: make(map[string]struct{}),
+		kick:      make(chan struct{}, 1),
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	data, err := os.ReadFile(filepath.Join(dir, jobsFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Join(ErrReadFailed, err)
+		}
+		return q, nil
+	}
+	var jobs []*Job
+	err = json.Unmarshal(data, &jobs)
+	if err != nil {
+		return nil, errors.Join(ErrReadFailed, err)
+	}
+	for _, j := range jobs {
+		if j.State == StateUploading {
+			// The upload was interrupted when Flux stopped
+			j.State = StateQueued
+			j.MachineID = ""
+		}
+		q.jobs[j.ID] = j
+	}
+	return q, nil
+}
+
+// Start begins scheduling jobs
+func (q *Queue) Start() {
+	q.subscription = q.sdcp.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	q.wg.Add(1)
+	go q.schedule()
+}
+
+// Stop stops scheduling jobs and cancels any uploads in progress, which are retried once the queue is restarted
+func (q *Queue) Stop() {
+	q.cancel()
+	if q.subscription != nil {
+		q.subscription.Close()
+	}
+	q.wg.Wait()
+}
+
+// Submit adds a job that prints the file read from r to the queue
+func (q *Queue) Submit(r io.Reader, filename string, size int64, options SubmitOptions) (*Job, error) {
+	filename = filepath.Base(filename)
+	if size <= 0 || filename == "." || filename == string(filepath.Separator) {
+		return nil, errors.Join(ErrSubmitFailed, ErrInvalidFile)
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+
+	j := &Job{
+		ID:          uuid.New().String(),
+		Filename:    filename,
+		Size:        size,
+		Priority:    options.Priority,
+		Constraints: options.Constraints,
+		State:       StateQueued,
+		MaxAttempts: options.MaxAttempts,
+		CreatedAt:   time.Now(),
+	}
+	if options.Hold {
+		j.State = StateHeld
+	}
+
+	err := os.MkdirAll(filepath.Join(q.dir, filesDirectory), 0700)
+	if err != nil {
+		return nil, errors.Join(ErrSubmitFailed, err)
+	}
+	f, err := os.Create(q.path(j))
+	if err != nil {
+		return nil, errors.Join(ErrSubmitFailed, err)
+	}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Close()
+	} else {
+		_ = f.Close()
+	}
+	if err == nil && n != size {
+		err = ErrInvalidFile
+	}
+	if err != nil {
+		_ = os.Remove(q.path(j))
+		return nil, errors.Join(ErrSubmitFailed, err)
+	}
+
+	// Sliced files can only be printed by machines with the resolution they were sliced for
+	metadata, err := slicefile.ParseFile(q.path(j))
+	if err == nil && j.Constraints.Resolution == "" {
+		j.Constraints.Resolution = metadata.Resolution()
+	}
+
+	q.mu.Lock()
+	q.jobs[j.ID] = j
+	q.persist()
+	c := *j
+	q.mu.Unlock()
+
+	q.logger.Info().Str("job", j.ID).Str("filename", filename).Int("priority", j.Priority).Msg("job submitted")
+	q.wake()
+	return &c, nil
+}
+
+// Jobs returns every job in the order they are scheduled in
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	jobs := make([]*Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, j)
+	}
+	slices.SortFunc(jobs, compare)
+	res := make([]Job, len(jobs))
+	for i, j := range jobs {
+		res[i] = *j
+	}
+	q.mu.Unlock()
+	return res
+}
+
+func (q *Queue) Job(id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	c := *j
+	return &c, nil
+}
+
+// Thumbnail returns the largest preview image of the file of a job
+func (q *Queue) Thumbnail(id string) (*slicefile.Thumbnail, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	path := q.path(j)
+	q.mu.Unlock()
+
+	metadata, err := slicefile.ParseFile(path)
+	if err != nil {
+		return nil, errors.Join(ErrNoThumbnail, err)
+	}
+	if len(metadata.Thumbnails) == 0 {
+		return nil, ErrNoThumbnail
+	}
+	return &metadata.Thumbnails[0], nil
+}
+
+// Hold stops a queued job from being scheduled until it is released
+func (q *Queue) Hold(id string) (*Job, error) {
+	return q.update(id, func(j *Job) error {
+		if j.State != StateQueued && j.State != StateHeld {
+			return ErrInvalidState
+		}
+		j.State = StateHeld
+		return nil
+	})
+}
+
+// Release allows a held job to be scheduled
+func (q *Queue) Release(id string) (*Job, error) {
+	return q.update(id, func(j *Job) error {
+		if j.State != StateHeld && j.State != StateQueued {
+			return ErrInvalidState
+		}
+		j.State = StateQueued
+		return nil
+	})
+}
+
+// Retry queues a failed or cancelled job again, resetting its attempts
+func (q *Queue) Retry(id string) (*Job, error) {
+	return q.update(id, func(j *Job) error {
+		if j.State != StateFailed && j.State != StateCancelled {
+			return ErrInvalidState
+		}
+		j.requeue()
+		j.Attempts = 0
+		j.Error = ""
+		return nil
+	})
+}
+
+// SetPriority changes the priority of a job
+func (q *Queue) SetPriority(id string, priority int) (*Job, error) {
+	return q.update(id, func(j *Job) error {
+		j.Priority = priority
+		return nil
+	})
+}
+
+// Cancel cancels a job, stopping the upload or print if it is assigned to a machine
+func (q *Queue) Cancel(id string) (*Job, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if j.State.Finished() {
+		q.mu.Unlock()
+		return nil, ErrInvalidState
+	}
+	state, machineID := j.State, j.MachineID
+	if cancel, ok := q.cancels[id]; ok {
+		cancel()
+	}
+	q.mu.Unlock()
+
+	if state == StatePrinting {
+		if m, ok := q.sdcp.GetMachine(machineID); ok {
+			ctx, cancel := q.context()
+			_, err := m.StopPrint(ctx)
+			cancel()
+			if err != nil {
+				return nil, errors.Join(ErrCancelFailed, err)
+			}
+		}
+	}
+
+	return q.update(id, func(j *Job) error {
+		j.finish(StateCancelled, nil)
+		return nil
+	})
+}
+
+// Delete removes a finished or queued job and its file from the queue
+func (q *Queue) Delete(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if j.State.Active() {
+		return ErrInvalidState
+	}
+	delete(q.jobs, id)
+	_ = os.Remove(q.path(j))
+	return q.write()
+}
+
+func (q *Queue) update(id string, update func(j *Job) error) (*Job, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	err := update(j)
+	if err != nil {
+		q.mu.Unlock()
+		return nil, err
+	}
+	q.persist()
+	c := *j
+	q.mu.Unlock()
+	q.wake()
+	return &c, nil
+}
+
+// wake runs the scheduler as soon as possible
+func (q *Queue) wake() {
+	select {
+	case q.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) path(j *Job) string {
+	return filepath.Join(q.dir, filesDirectory, j.ID)
+}
+
+// write persists every job, q.mu must be held
+func (q *Queue) write() error {
+	jobs := make([]*Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, j)
+	}
+	slices.SortFunc(jobs, compare)
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	err = utils.WriteFileAtomic(filepath.Join(q.dir, jobsFile), data)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	return nil
+}
+
+func (j *Job) requeue() {
+	j.State = StateQueued
+	j.MachineID = ""
+	j.TaskID = ""
+	j.StartedAt = time.Time{}
+	j.FinishedAt = time.Time{}
+}
+
+func (j *Job) finish(state State, err error) {
+	j.State = state
+	j.FinishedAt = time.Now()
+	if err != nil {
+		j.Error = err.Error()
+	}
+}
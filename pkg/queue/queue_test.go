@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/sdcp/sdcptest"
+)
+
+func TestQueue(t *testing.T) {
+	logger := logging.Test(t, logging.Slog, t.Name())
+	s := sdcp.New(logger, nil)
+	t.Cleanup(s.Close)
+
+	saturn, err := sdcptest.NewPrinter(sdcptest.Config{})
+	require.NoError(t, err)
+	t.Cleanup(saturn.Close)
+	mars, err := sdcptest.NewPrinter(sdcptest.Config{Model: "Mars Simulator", LayerTime: 100 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(mars.Close)
+	require.NoError(t, s.Register(saturn.Registration()))
+	require.NoError(t, s.Register(mars.Registration()))
+
+	dir := t.TempDir()
+	q, err := New(s, dir, logger)
+	require.NoError(t, err)
+	q.Start()
+	t.Cleanup(q.Stop)
+
+	data := bytes.Repeat([]byte("flux"), 1024)
+	submit := func(name string, options SubmitOptions) *Job {
+		j, err := q.Submit(bytes.NewReader(data), name, int64(len(data)), options)
+		require.NoError(t, err)
+		return j
+	}
+	waitFor := func(id string, state State) *Job {
+		var j *Job
+		require.Eventually(t, func() bool {
+			j, err = q.Job(id)
+			require.NoError(t, err)
+			return j.State == state
+		}, 10*time.Second, 10*time.Millisecond)
+		return j
+	}
+
+	_, err = q.Submit(bytes.NewReader(data), "model.ctb", int64(len(data))+1, SubmitOptions{})
+	require.ErrorIs(t, err, ErrInvalidFile)
+
+	held := submit("held.ctb", SubmitOptions{Hold: true, Constraints: Constraints{Model: sdcptest.DefaultModel}})
+	j := waitFor(submit("saturn.ctb", SubmitOptions{Constraints: Constraints{Model: sdcptest.DefaultModel}}).ID, StateCompleted)
+	require.Equal(t, saturn.ID(), j.MachineID)
+	require.Equal(t, 1, j.Attempts)
+	require.Equal(t, StateHeld, waitFor(held.ID, StateHeld).State)
+
+	_, err = q.Release(held.ID)
+	require.NoError(t, err)
+	require.Equal(t, saturn.ID(), waitFor(held.ID, StateCompleted).MachineID)
+
+	// Neither machine supports STL files, so the job is never scheduled
+	unsupported := submit("model.stl", SubmitOptions{})
+	retried := submit("mars.ctb", SubmitOptions{MaxAttempts: 2, Priority: 1, Constraints: Constraints{Model: "mars simulator"}})
+	j = waitFor(retried.ID, StatePrinting)
+	require.Equal(t, mars.ID(), j.MachineID)
+	require.Eventually(t, func() bool {
+		j, err = q.Job(retried.ID)
+		require.NoError(t, err)
+		return j.TaskID != ""
+	}, 10*time.Second, 10*time.Millisecond)
+	require.True(t, mars.FailPrint(sdcp.TaskErrorResinLack))
+	j = waitFor(retried.ID, StateCompleted)
+	require.Equal(t, 2, j.Attempts)
+	require.Len(t, mars.History(), 2)
+
+	cancelled := waitFor(submit("cancelled.ctb", SubmitOptions{Constraints: Constraints{Machines: []string{mars.ID()}}}).ID, StatePrinting)
+	_, err = q.Cancel(cancelled.ID)
+	require.NoError(t, err)
+	require.Equal(t, StateCancelled, waitFor(cancelled.ID, StateCancelled).State)
+	require.Eventually(t, func() bool {
+		return mars.Status().PrintInfo.Status == sdcp.PrintInfoStatusStopped
+	}, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, q.Delete(cancelled.ID))
+	_, err = q.Job(cancelled.ID)
+	require.ErrorIs(t, err, ErrJobNotFound)
+
+	q.Stop()
+	restored, err := New(s, dir, logger)
+	require.NoError(t, err)
+	require.Len(t, restored.Jobs(), 4)
+	j, err = restored.Job(unsupported.ID)
+	require.NoError(t, err)
+	require.Equal(t, StateQueued, j.State)
+	t.Cleanup(restored.Stop)
+}
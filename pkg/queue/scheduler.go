@@ -0,0 +1,244 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// schedule runs the scheduler whenever a machine reports its status, a job changes, or every scheduleInterval
+func (q *Queue) schedule() {
+	defer q.wg.Done()
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	events := q.subscription.C
+	q.tick()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if e.Type == sdcp.EventTypeStatus {
+				q.track(e.MachineID, e.Status)
+			}
+		case <-q.kick:
+		case <-ticker.C:
+		}
+		q.tick()
+	}
+}
+
+// track records the task ID of the job printing on the machine once the machine reports that it started printing it
+func (q *Queue) track(machineID string, status *sdcp.Status) {
+	if status == nil || status.PrintInfo.TaskId == "" || !slices.Contains(status.CurrentStatus, sdcp.MachineStatusPrinting) {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		// The machine can report that it is printing before the start print request returns
+		if j.State.Active() && j.MachineID == machineID && j.TaskID == "" && path.Base(status.PrintInfo.Filename) == j.Filename {
+			j.TaskID = status.PrintInfo.TaskId
+			q.logger.Debug().Str("job", j.ID).Str("task", j.TaskID).Msg("job is printing")
+			q.persist()
+		}
+	}
+}
+
+// tick checks the progress of every printing job, then assigns queued jobs to idle compatible machines
+func (q *Queue) tick() {
+	machines := q.sdcp.Machines()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	busy := make(map[string]struct{})
+	var queued []*Job
+	for _, j := range q.jobs {
+		switch j.State {
+		case StateQueued:
+			queued = append(queued, j)
+		case StateUploading:
+			busy[j.MachineID] = struct{}{}
+		case StatePrinting:
+			busy[j.MachineID] = struct{}{}
+			q.check(j)
+		}
+	}
+
+	slices.SortFunc(queued, compare)
+	for _, j := range queued {
+		for _, m := range machines {
+			if _, ok := busy[m.ID()]; ok || !idle(m) || !j.Compatible(m) {
+				continue
+			}
+			busy[m.ID()] = struct{}{}
+			q.assign(j, m)
+			break
+		}
+	}
+}
+
+// check fails a printing job whose machine is gone or never started printing, and resolves
+// the outcome of its print task once the machine is idle again, q.mu must be held
+func (q *Queue) check(j *Job) {
+	m, ok := q.sdcp.GetMachine(j.MachineID)
+	if !ok {
+		q.fail(j, ErrMachineNotFound)
+		return
+	}
+	if j.TaskID == "" {
+		if time.Since(j.StartedAt) > startTimeout {
+			q.fail(j, ErrPrintNotStarted)
+		}
+		return
+	}
+	if _, ok := q.resolving[j.ID]; ok || !idle(m) {
+		return
+	}
+	q.resolving[j.ID] = struct{}{}
+	q.wg.Add(1)
+	go q.resolve(j.ID, j.TaskID, m)
+}
+
+// assign uploads the job to the machine and starts printing it, q.mu must be held
+func (q *Queue) assign(j *Job, m *sdcp.Machine) {
+	j.State = StateUploading
+	j.MachineID = m.ID()
+	j.Attempts++
+	q.persist()
+	q.logger.Info().Str("job", j.ID).Str("machine", m.ID()).Int("attempt", j.Attempts).Msg("job scheduled")
+
+	ctx, cancel := context.WithCancel(q.ctx)
+	q.cancels[j.ID] = cancel
+	q.wg.Add(1)
+	go q.run(ctx, *j, m)
+}
+
+// run uploads the job file to the machine and starts the print
+func (q *Queue) run(ctx context.Context, j Job, m *sdcp.Machine) {
+	defer q.wg.Done()
+	err := q.start(ctx, &j, m)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cancelled := ctx.Err() != nil
+	q.cancels[j.ID]()
+	delete(q.cancels, j.ID)
+	current, ok := q.jobs[j.ID]
+	if !ok || current.State != StateUploading || current.MachineID != m.ID() || cancelled {
+		// The job was cancelled, or the queue is stopping
+		return
+	}
+	if err != nil {
+		q.logger.Warn().Err(err).Str("job", j.ID).Str("machine", m.ID()).Msg("failed to start job")
+		q.fail(current, err)
+		return
+	}
+	current.State = StatePrinting
+	current.StartedAt = time.Now()
+	q.persist()
+	q.wake()
+}
+
+func (q *Queue) start(ctx context.Context, j *Job, m *sdcp.Machine) error {
+	f, err := os.Open(q.path(j))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	_, err = m.UploadFile(ctx, f, j.Filename, j.Size)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	_, err = m.StartPrint(ctx, j.Filename, 0)
+	return err
+}
+
+// resolve looks up the print task of the job in the machine history to determine whether it completed
+func (q *Queue) resolve(id string, taskID string, m *sdcp.Machine) {
+	defer q.wg.Done()
+	ctx, cancel := q.context()
+	tasks, err := m.TaskDetails(ctx, taskID)
+	cancel()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.resolving, id)
+	j, ok := q.jobs[id]
+	if !ok || j.State != StatePrinting || j.TaskID != taskID || q.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		q.logger.Warn().Err(err).Str("job", id).Msg("failed to retrieve print task details")
+		return
+	}
+	if len(tasks) == 0 {
+		q.fail(j, ErrTaskNotFound)
+		return
+	}
+
+	task := tasks[0]
+	switch task.TaskStatus {
+	case sdcp.TaskStatusCompleted:
+		j.finish(StateCompleted, nil)
+		q.persist()
+		q.logger.Info().Str("job", id).Str("machine", j.MachineID).Msg("job completed")
+		q.wake()
+	case sdcp.TaskStatusStopped:
+		// Stopping a print is a deliberate action, so the job is not retried
+		j.finish(StateFailed, ErrPrintStopped)
+		q.persist()
+		q.wake()
+	case sdcp.TaskStatusExceptional:
+		q.fail(j, fmt.Errorf("%w: %s", ErrTaskFailed, task.ErrorStatusReason))
+	default:
+		if m.Status().PrintInfo.TaskId == taskID {
+			// The machine has not finished recording the task yet
+			return
+		}
+		q.fail(j, fmt.Errorf("%w: %s", ErrUnknownTaskState, task.TaskStatus))
+	}
+}
+
+// fail records a failed attempt of the job, queueing it again if it has attempts left, q.mu must be held
+func (q *Queue) fail(j *Job, err error) {
+	j.Error = err.Error()
+	if j.Attempts >= j.MaxAttempts {
+		j.finish(StateFailed, errors.Join(ErrMaximumAttempts, err))
+		q.logger.Warn().Err(err).Str("job", j.ID).Msg("job failed")
+	} else {
+		j.requeue()
+		q.logger.Warn().Err(err).Str("job", j.ID).Int("attempt", j.Attempts).Msg("job attempt failed, retrying")
+		q.wake()
+	}
+	q.persist()
+}
+
+// persist writes the queue, logging any error, q.mu must be held
+func (q *Queue) persist() {
+	err := q.write()
+	if err != nil {
+		q.logger.Error().Err(err).Msg("failed to persist queue")
+	}
+}
+
+func (q *Queue) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(q.ctx, requestTimeout)
+}
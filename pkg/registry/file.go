@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+var _ sdcp.Registry = (*File)(nil)
+
+var (
+	ErrReadFailed  = errors.New("failed to read registry file")
+	ErrWriteFailed = errors.New("failed to write registry file")
+)
+
+// File is a sdcp.Registry that persists registrations as JSON in a local file
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFile(path string) *File {
+	return &File{
+		path: path,
+	}
+}
+
+func (f *File) List() ([]sdcp.Registration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read()
+}
+
+func (f *File) Put(registration sdcp.Registration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	registrations, err := f.read()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(registrations, func(r sdcp.Registration) bool {
+		return r.ID == registration.ID
+	})
+	if i < 0 {
+		registrations = append(registrations, registration)
+	} else {
+		registrations[i] = registration
+	}
+	return f.write(registrations)
+}
+
+func (f *File) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	registrations, err := f.read()
+	if err != nil {
+		return err
+	}
+	registrations = slices.DeleteFunc(registrations, func(r sdcp.Registration) bool {
+		return r.ID == id
+	})
+	return f.write(registrations)
+}
+
+func (f *File) read() ([]sdcp.Registration, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Join(ErrReadFailed, err)
+	}
+	var registrations []sdcp.Registration
+	err = json.Unmarshal(data, &registrations)
+	if err != nil {
+		return nil, errors.Join(ErrReadFailed, err)
+	}
+	return registrations, nil
+}
+
+func (f *File) write(registrations []sdcp.Registration) error {
+	if registrations == nil {
+		registrations = []sdcp.Registration{}
+	}
+	data, err := json.MarshalIndent(registrations, "", "  ")
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	err = utils.WriteFileAtomic(f.path, data)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	return nil
+}
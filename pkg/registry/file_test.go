@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+func TestFile(t *testing.T) {
+	f := NewFile(filepath.Join(t.TempDir(), "registry", "machines.json"))
+
+	registrations, err := f.List()
+	require.NoError(t, err)
+	require.Empty(t, registrations)
+
+	first := sdcp.Registration{ID: "first", IP: "10.0.0.1", Label: "First", Tags: []string{"shop"}}
+	second := sdcp.Registration{ID: "second", IP: "10.0.0.2"}
+	require.NoError(t, f.Put(first))
+	require.NoError(t, f.Put(second))
+
+	first.IP = "10.0.0.3"
+	require.NoError(t, f.Put(first))
+
+	registrations, err = f.List()
+	require.NoError(t, err)
+	require.Equal(t, []sdcp.Registration{first, second}, registrations)
+
+	require.NoError(t, f.Delete("first"))
+	require.NoError(t, f.Delete("unknown"))
+
+	registrations, err = f.List()
+	require.NoError(t, err)
+	require.Equal(t, []sdcp.Registration{second}, registrations)
+}
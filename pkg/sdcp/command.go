@@ -0,0 +1,121 @@
+package sdcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrChangePrinterNameFailed = errors.New("change printer name failed")
+	ErrRejected                = errors.New("request rejected by machine")
+	ErrDecodeFailed            = errors.New("failed to decode response")
+)
+
+var (
+	ErrStreamLimit          = errors.New("maximum number of video streams reached")
+	ErrStreamCameraNotFound = errors.New("camera does not exist")
+	ErrStreamUnknown        = errors.New("unknown video stream error")
+)
+
+// Err returns the error associated with the StreamAck, or nil if the StreamAck is StreamAckSuccess
+func (a StreamAck) Err() error {
+	switch a {
+	case StreamAckSuccess:
+		return nil
+	case StreamAckLimit:
+		return ErrStreamLimit
+	case StreamAckNotExist:
+		return ErrStreamCameraNotFound
+	default:
+		return fmt.Errorf("%w: %d", ErrStreamUnknown, a)
+	}
+}
+
+// acknowledged is implemented by every response, returning the error the machine
+// answered the request with, or nil if the request was acknowledged
+type acknowledged interface {
+	ack() error
+}
+
+// typedCommand binds a Command to the types of its request and response, and to the error that
+// wraps every failure of the command
+type typedCommand[Req any, Res acknowledged] struct {
+	command Command
+	err     error
+}
+
+var (
+	statusRefreshCommand       = typedCommand[StatusRefreshRequest, StatusRefreshResponse]{CommandStatusRefresh, ErrStatusRefreshFailed}
+	attributesRefreshCommand   = typedCommand[AttributesRefreshRequest, AttributesRefreshResponse]{CommandAttributesRefresh, ErrAttributesRefreshFailed}
+	startPrintCommand          = typedCommand[StartPrintingRequest, StartPrintingResponse]{CommandStartPrint, ErrStartPrintFailed}
+	pausePrintCommand          = typedCommand[PausePrintingRequest, PausePrintingResponse]{CommandPausePrint, ErrPausePrintFailed}
+	stopPrintCommand           = typedCommand[StopPrintingRequest, StopPrintingResponse]{CommandStopPrint, ErrStopPrintFailed}
+	resumePrintCommand         = typedCommand[ResumePrintingRequest, ResumePrintingResponse]{CommandResumePrint, ErrResumePrintFailed}
+	stopFeedingMaterialCommand = typedCommand[StopFeedingMaterialRequest, StopFeedingMaterialResponse]{CommandStopFeedingMaterial, ErrStopFeedingMaterialFailed}
+	skipPreheatingCommand      = typedCommand[SkipPreheatingRequest, SkipPreheatingResponse]{CommandSkipPreheating, ErrSkipPreheatingFailed}
+	changePrinterNameCommand   = typedCommand[ChangePrinterNameRequest, ChangePrinterNameResponse]{CommandChangePrinterName, ErrChangePrinterNameFailed}
+	terminateTransferCommand   = typedCommand[TerminateFileTransferRequest, TerminateFileTransferResponse]{CommandTerminateFileTransfer, ErrTerminateFileTransferFailed}
+	retrieveFileListCommand    = typedCommand[RetrieveFileListRequest, RetrieveFileListResponse]{CommandRetrieveFileList, ErrRetrieveFileListFailed}
+	batchDeleteFilesCommand    = typedCommand[BatchDeleteFilesRequest, BatchDeleteFilesResponse]{CommandBatchDeleteFiles, ErrBatchDeleteFilesFailed}
+	historicalTasksCommand     = typedCommand[RetrieveHistoricalTasksRequest, RetrieveHistoricalTasksResponse]{CommandRetrieveHistoricalTasks, ErrRetrieveHistoricalTasksFailed}
+	taskDetailsCommand         = typedCommand[RetrieveTaskDetailsRequest, RetrieveTaskDetailsResponse]{CommandRetrieveTaskDetails, ErrRetrieveTaskDetailsFailed}
+	videoStreamCommand         = typedCommand[EnableDisableVideoStreamRequest, EnableDisableVideoStreamResponse]{CommandEnableDisableVideoStream, ErrEnableDisableVideoFailed}
+	timeLapseCommand           = typedCommand[EnableDisableTimeLapseRequest, EnableDisableTimeLapseResponse]{CommandEnableDisableTimeLapse, ErrSetTimeLapseFailed}
+)
+
+// send sends the request to the machine and decodes its response. Every error wraps the error of the
+// command, and the response is returned along with the error if the machine rejected the request.
+func (c typedCommand[Req, Res]) send(m *Machine, ctx context.Context, req Req) (*Res, error) {
+	response, err := request(m, c.command, req, ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Str("command", c.command.String()).Msg("error during request")
+		return nil, errors.Join(c.err, err)
+	}
+
+	res := new(Res)
+	err = json.Unmarshal(response.Data.Data, res)
+	if err != nil {
+		m.logger.Error().Err(err).Str("command", c.command.String()).Msg("error decoding response")
+		return nil, errors.Join(c.err, ErrDecodeFailed, err)
+	}
+	if err = (*res).ack(); err != nil {
+		m.logger.Warn().Err(err).Str("command", c.command.String()).Msg("machine rejected request")
+		return res, errors.Join(c.err, err)
+	}
+	return res, nil
+}
+
+// rejected returns the error of a plain acknowledgement, where any value other than 0 is a rejection
+func rejected(ack int) error {
+	if ack != 0 {
+		return fmt.Errorf("%w: ack %d", ErrRejected, ack)
+	}
+	return nil
+}
+
+func (r StatusRefreshResponse) ack() error            { return rejected(r.Ack) }
+func (r AttributesRefreshResponse) ack() error        { return rejected(r.Ack) }
+func (r StartPrintingResponse) ack() error            { return r.Ack.Err() }
+func (r PausePrintingResponse) ack() error            { return r.Ack.Err() }
+func (r StopPrintingResponse) ack() error             { return r.Ack.Err() }
+func (r ResumePrintingResponse) ack() error           { return r.Ack.Err() }
+func (r StopFeedingMaterialResponse) ack() error      { return r.Ack.Err() }
+func (r SkipPreheatingResponse) ack() error           { return r.Ack.Err() }
+func (r ChangePrinterNameResponse) ack() error        { return rejected(r.Ack) }
+func (r TerminateFileTransferResponse) ack() error    { return r.Ack.Err() }
+func (r RetrieveFileListResponse) ack() error         { return rejected(r.Ack) }
+func (r RetrieveHistoricalTasksResponse) ack() error  { return rejected(r.Ack) }
+func (r RetrieveTaskDetailsResponse) ack() error      { return rejected(r.Ack) }
+func (r EnableDisableVideoStreamResponse) ack() error { return r.Ack.Err() }
+func (r EnableDisableTimeLapseResponse) ack() error   { return rejected(r.Ack) }
+
+// ack reports the files and folders the machine failed to delete before a rejection, as the machine
+// may delete some of them
+func (r BatchDeleteFilesResponse) ack() error {
+	if len(r.ErrData) > 0 {
+		return &DeleteFilesError{Failed: r.ErrData}
+	}
+	return rejected(r.Ack)
+}
@@ -0,0 +1,205 @@
+package sdcp
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrNotConnected = errors.New("machine not connected")
+	ErrDisconnected = errors.New("machine disconnected")
+)
+
+const (
+	initialReconnectBackoff = time.Second
+	maximumReconnectBackoff = time.Minute
+)
+
+type ConnectionState string
+
+const (
+	ConnectionStateConnecting ConnectionState = "connecting"
+	ConnectionStateOnline     ConnectionState = "online"
+	ConnectionStateOffline    ConnectionState = "offline"
+)
+
+// State returns the current state of the connection to the machine
+func (m *Machine) State() ConnectionState {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.state
+}
+
+func (m *Machine) setState(state ConnectionState) {
+	m.stateMu.Lock()
+	previous := m.state
+	m.state = state
+	m.stateMu.Unlock()
+	if previous != state {
+		m.logger.Debug().Str("previous", string(previous)).Str("state", string(state)).Msg("connection state changed")
+		m.publish(Event{Type: EventTypeState, State: state})
+	}
+}
+
+func (m *Machine) connected() bool {
+	m.connMu.RLock()
+	defer m.connMu.RUnlock()
+	return m.conn != nil
+}
+
+// checkConnected returns ErrDisconnected if the machine is not connected
+func (m *Machine) checkConnected() error {
+	if !m.connected() {
+		return ErrDisconnected
+	}
+	return nil
+}
+
+func (m *Machine) dial() (*websocket.Conn, error) {
+	m.addressMu.RLock()
+	u := m.url.String()
+	m.addressMu.RUnlock()
+	conn, _, err := websocket.DefaultDialer.DialContext(m.ctx, u, nil)
+	return conn, err
+}
+
+// start begins reading from conn, returning a channel that is closed once the connection has been lost
+func (m *Machine) start(conn *websocket.Conn) <-chan struct{} {
+	var options ConnectionOptions
+	if m.hooks.connection != nil {
+		options = m.hooks.connection()
+	}
+	options.defaults()
+	w := newWriter(options.QueueSize)
+
+	m.connMu.Lock()
+	m.conn = conn
+	m.writer = w
+	m.connMu.Unlock()
+
+	done := make(chan struct{})
+	m.wg.Add(2)
+	go m.handle(conn, options.StaleTimeout, done)
+	go m.write(conn, w, options, done)
+	return done
+}
+
+// sync refreshes the status and attributes of the machine, waiting for both to be pushed
+func (m *Machine) sync() error {
+	_, err := m.StatusRefreshWait(m.ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to refresh status")
+		return errors.Join(ErrStatusRefreshFailed, err)
+	}
+
+	_, err = m.AttributesRefreshWait(m.ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to refresh attributes")
+		return errors.Join(ErrAttributesRefreshFailed, err)
+	}
+	return nil
+}
+
+// disconnected is called by the read loop when conn is lost. It fails every in-flight
+// request and wakes any goroutines waiting on a status or attributes push
+func (m *Machine) disconnected(conn *websocket.Conn) {
+	m.connMu.Lock()
+	if m.conn == conn {
+		m.conn = nil
+		m.writer = nil
+	}
+	m.connMu.Unlock()
+	_ = conn.Close()
+	m.setState(ConnectionStateOffline)
+
+	m.inflightMu.Lock()
+	for id, i := range m.inflight {
+		i.err = ErrDisconnected
+		close(i.signal)
+		delete(m.inflight, id)
+	}
+	m.inflightMu.Unlock()
+
+	m.status.wake()
+	m.attributes.wake()
+}
+
+// supervise waits for the connection to the machine to be lost and then redials it
+// with exponential backoff, re-syncing the status and attributes once reconnected
+func (m *Machine) supervise(done <-chan struct{}) {
+	defer m.wg.Done()
+	var b reconnectBackoff
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-done:
+		}
+
+		if m.ctx.Err() != nil {
+			return
+		}
+		m.logger.Warn().Msg("machine offline, reconnecting")
+
+		for {
+			attempt := b.attempt
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(b.next()):
+			case <-m.redial:
+			}
+
+			m.setState(ConnectionStateConnecting)
+			conn, err := m.dial()
+			if err != nil {
+				m.setState(ConnectionStateOffline)
+				m.logger.Debug().Err(err).Int("attempt", attempt).Msg("failed to reconnect to machine")
+				continue
+			}
+
+			done = m.start(conn)
+			err = m.sync()
+			if err != nil {
+				_ = conn.Close()
+				<-done
+				continue
+			}
+
+			b.reset()
+			m.setState(ConnectionStateOnline)
+			m.logger.Info().Int("attempt", attempt).Msg("reconnected to machine")
+			break
+		}
+	}
+}
+
+// reconnectBackoff counts the attempts made to reconnect to a machine since the connection was last
+// lost, and is reset once the machine has been reconnected to
+type reconnectBackoff struct {
+	attempt int
+}
+
+// next returns the time to wait before the next attempt
+func (b *reconnectBackoff) next() time.Duration {
+	d := backoff(b.attempt)
+	b.attempt++
+	return d
+}
+
+func (b *reconnectBackoff) reset() {
+	b.attempt = 0
+}
+
+// backoff returns the time to wait before the given reconnection attempt, doubling
+// from initialReconnectBackoff up to maximumReconnectBackoff with up to 20% jitter
+func backoff(attempt int) time.Duration {
+	d := maximumReconnectBackoff
+	if attempt < 16 {
+		d = min(initialReconnectBackoff<<attempt, maximumReconnectBackoff)
+	}
+	return d - time.Duration(rand.Int64N(int64(d)/5))
+}
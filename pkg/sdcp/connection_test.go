@@ -0,0 +1,28 @@
+package sdcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	var b reconnectBackoff
+	var previous time.Duration
+	for attempt := 0; attempt < 20; attempt++ {
+		d := b.next()
+		maximum := min(initialReconnectBackoff<<min(attempt, 16), maximumReconnectBackoff)
+		require.LessOrEqual(t, d, maximum)
+		require.Greater(t, d, maximum*4/5)
+		if maximum < maximumReconnectBackoff {
+			require.Greater(t, d, previous)
+		}
+		previous = d
+	}
+
+	// Reconnecting starts the next outage from the initial backoff again
+	b.reset()
+	require.LessOrEqual(t, b.next(), initialReconnectBackoff)
+	require.Equal(t, 1, b.attempt)
+}
@@ -20,6 +20,7 @@ var (
 const (
 	BroadcastIP   = "255.255.255.255"
 	BroadcastPort = 3000
+	APIPort       = 3030
 
 	maximumDiscoverTime = 5 * time.Second
 )
@@ -32,7 +33,82 @@ var (
 	discoverMessage = []byte("M99999")
 )
 
+type DiscoveryEventType string
+
+const (
+	DiscoveryEventTypeRegistered     DiscoveryEventType = "registered"      // A discovered machine was registered
+	DiscoveryEventTypeAddressChanged DiscoveryEventType = "address_changed" // A registered machine was discovered at a new IP address
+)
+
+// DiscoveryEvent is a change made to the registered machines because of a discover message
+type DiscoveryEvent struct {
+	Type         DiscoveryEventType
+	IP           string
+	PreviousIP   string
+	MachineName  string
+	MachineModel string
+}
+
+// RegisterDiscovered registers a machine that answered a discover message, labelled with its name,
+// whose SDCP endpoints listen on port. A discovery event is published once it is registered.
+func (s *SDCP) RegisterDiscovered(data DiscoverData, port int) error {
+	err := s.Register(Registration{
+		ID:    data.MainboardID,
+		IP:    data.MainboardIP,
+		Port:  port,
+		Label: data.MachineName,
+	})
+	if err != nil {
+		return err
+	}
+	if m, ok := s.GetMachine(data.MainboardID); ok {
+		m.publish(Event{Type: EventTypeDiscovery, Discovery: &DiscoveryEvent{
+			Type:         DiscoveryEventTypeRegistered,
+			IP:           data.MainboardIP,
+			MachineName:  data.MachineName,
+			MachineModel: data.MachineModel,
+		}})
+	}
+	return nil
+}
+
+// UpdateDiscovered updates the IP address of a registered machine that answered a discover message from a
+// new address, redialling it at that address in the background, and returns true if the address changed. A
+// discovery event is published once the address is updated.
+func (s *SDCP) UpdateDiscovered(data DiscoverData) (bool, error) {
+	m, ok := s.GetMachine(data.MainboardID)
+	if !ok {
+		return false, ErrNotRegistered
+	}
+	previous, changed := m.setAddress(data.MainboardIP)
+	if !changed {
+		return false, nil
+	}
+
+	s.logger.Info().Str("id", m.id).Str("previous", previous).Str("ip", data.MainboardIP).Msg("machine address changed")
+	if s.registry != nil {
+		err := s.registry.Put(m.registration())
+		if err != nil {
+			s.logger.Error().Err(err).Str("id", m.id).Msg("failed to persist machine registration")
+		}
+	}
+	m.publish(Event{Type: EventTypeDiscovery, Discovery: &DiscoveryEvent{
+		Type:         DiscoveryEventTypeAddressChanged,
+		IP:           data.MainboardIP,
+		PreviousIP:   previous,
+		MachineName:  data.MachineName,
+		MachineModel: data.MachineModel,
+	}})
+	return true, nil
+}
+
+// Discover broadcasts a discover message on the local network and returns every machine that answers
 func Discover(logger types.Logger, ctx context.Context) ([]DiscoverMessage, error) {
+	return DiscoverAddress(logger, ctx, broadcastAddress)
+}
+
+// DiscoverAddress sends a discover message to the given address and returns every machine that answers
+func DiscoverAddress(logger types.Logger, ctx context.Context, address *net.UDPAddr) ([]DiscoverMessage, error) {
 	connection, err := net.ListenUDP("udp", nil)
 	if err != nil {
 		return nil, errors.Join(ErrUnableCreateUDPSocket, err)
@@ -40,7 +116,7 @@ func Discover(logger types.Logger, ctx context.Context) ([]DiscoverMessage, erro
 
 	l := logger.SubLogger("discover")
 	l.Debug().Str("listen", connection.LocalAddr().String()).Msg("broadcasting discover message")
-	_, err = connection.WriteToUDP(discoverMessage, broadcastAddress)
+	_, err = connection.WriteToUDP(discoverMessage, address)
 	if err != nil {
 		_ = connection.Close()
 		return nil, errors.Join(ErrBroadcastFailed, err)
@@ -0,0 +1,160 @@
+package sdcp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultSubscriptionBuffer = 64
+)
+
+type EventType string
+
+const (
+	EventTypeStatus     EventType = "status"
+	EventTypeAttributes EventType = "attributes"
+	EventTypeState      EventType = "state"
+	EventTypeError      EventType = "error"
+	EventTypeNotice     EventType = "notice"
+	EventTypePrint      EventType = "print"
+	EventTypeDiscovery  EventType = "discovery"
+)
+
+// Event is published whenever a machine pushes a new status, attributes, error or notice,
+// its connection state changes, a status push changes the lifecycle of its print, or it is
+// registered or readdressed because of a discover message. Only the field matching Type is set.
+type Event struct {
+	Type       EventType
+	MachineID  string
+	Time       time.Time
+	Status     *Status
+	Attributes *Attributes
+	State      ConnectionState
+	Error      *ErrorData
+	Notice     *NotificationData
+	Print      *PrintEvent
+	Discovery  *DiscoveryEvent
+}
+
+// Subscription receives the events published for the machines it is subscribed to on C. Events are
+// buffered per subscription and dropped when the buffer is full, so a slow subscriber never blocks a machine.
+type Subscription struct {
+	C <-chan Event
+
+	c        chan Event
+	machines map[string]struct{}
+	dropped  atomic.Uint64
+	broker   *broker
+}
+
+// Dropped returns the number of events that were dropped because the subscription's buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes C
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+func (s *Subscription) matches(e Event) bool {
+	if len(s.machines) == 0 {
+		return true
+	}
+	_, ok := s.machines[e.MachineID]
+	return ok
+}
+
+type broker struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *broker) subscribe(buffer int, machineIDs []string) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultSubscriptionBuffer
+	}
+	s := &Subscription{
+		c:        make(chan Event, buffer),
+		machines: make(map[string]struct{}, len(machineIDs)),
+		broker:   b,
+	}
+	s.C = s.c
+	for _, id := range machineIDs {
+		s.machines[id] = struct{}{}
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		close(s.c)
+	} else {
+		b.subscriptions[s] = struct{}{}
+	}
+	b.mu.Unlock()
+	return s
+}
+
+func (b *broker) unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	if _, ok := b.subscriptions[s]; ok {
+		delete(b.subscriptions, s)
+		close(s.c)
+	}
+	b.mu.Unlock()
+}
+
+func (b *broker) publish(e Event) {
+	b.mu.RLock()
+	for s := range b.subscriptions {
+		if !s.matches(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+	b.mu.RUnlock()
+}
+
+func (b *broker) close() {
+	b.mu.Lock()
+	b.closed = true
+	for s := range b.subscriptions {
+		delete(b.subscriptions, s)
+		close(s.c)
+	}
+	b.mu.Unlock()
+}
+
+// Subscribe returns a Subscription to the events of this machine, buffering up to buffer events
+func (m *Machine) Subscribe(buffer int) *Subscription {
+	return m.events.subscribe(buffer, nil)
+}
+
+// Subscribe returns a Subscription to the events of the given machines, or of every
+// registered machine if no machine IDs are given, buffering up to buffer events
+func (s *SDCP) Subscribe(buffer int, machineIDs ...string) *Subscription {
+	return s.events.subscribe(buffer, machineIDs)
+}
+
+func (m *Machine) publish(e Event) {
+	e.MachineID = m.id
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	m.events.publish(e)
+	if m.hooks.publish != nil {
+		m.hooks.publish(e)
+	}
+}
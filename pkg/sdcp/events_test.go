@@ -0,0 +1,37 @@
+package sdcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker(t *testing.T) {
+	b := newBroker()
+	all := b.subscribe(1, nil)
+	filtered := b.subscribe(1, []string{"second"})
+
+	b.publish(Event{Type: EventTypeStatus, MachineID: "first"})
+	b.publish(Event{Type: EventTypeStatus, MachineID: "second"})
+
+	e := <-all.C
+	require.Equal(t, "first", e.MachineID)
+	require.Equal(t, uint64(1), all.Dropped())
+
+	e = <-filtered.C
+	require.Equal(t, "second", e.MachineID)
+	require.Equal(t, uint64(0), filtered.Dropped())
+
+	filtered.Close()
+	filtered.Close()
+	_, ok := <-filtered.C
+	require.False(t, ok)
+
+	b.close()
+	_, ok = <-all.C
+	require.False(t, ok)
+
+	closed := b.subscribe(1, nil)
+	_, ok = <-closed.C
+	require.False(t, ok)
+}
@@ -0,0 +1,117 @@
+package sdcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrRetrieveFileListFailed = errors.New("retrieve file list failed")
+	ErrBatchDeleteFilesFailed = errors.New("batch delete files failed")
+	ErrInvalidPath            = errors.New("invalid path")
+	ErrMaximumDepthExceeded   = errors.New("maximum folder depth exceeded")
+)
+
+const (
+	maximumWalkDepth = 16
+)
+
+// DeleteFilesError is returned by DeleteFiles when the machine reports that
+// some of the requested files or folders could not be deleted
+type DeleteFilesError struct {
+	Failed []Path
+}
+
+func (e *DeleteFilesError) Error() string {
+	failed := make([]string, len(e.Failed))
+	for i, p := range e.Failed {
+		failed[i] = string(p)
+	}
+	return fmt.Sprintf("failed to delete %d file(s) or folder(s): %s", len(e.Failed), strings.Join(failed, ", "))
+}
+
+// Storage is the usage of a single storage device on a machine
+type Storage struct {
+	StorageType StorageType
+	UsedSize    int
+	TotalSize   int
+}
+
+func (m *Machine) ListFiles(ctx context.Context, path Path) (*RetrieveFileListResponse, error) {
+	if !path.Valid() {
+		return nil, errors.Join(ErrRetrieveFileListFailed, ErrInvalidPath)
+	}
+
+	return retrieveFileListCommand.send(m, ctx, RetrieveFileListRequest{Url: path})
+}
+
+// WalkFiles lists every file and folder under path, descending into folders recursively
+func (m *Machine) WalkFiles(ctx context.Context, path Path) ([]FileList, error) {
+	return m.walkFiles(ctx, path, 0)
+}
+
+func (m *Machine) walkFiles(ctx context.Context, path Path, depth int) ([]FileList, error) {
+	if depth > maximumWalkDepth {
+		return nil, errors.Join(ErrRetrieveFileListFailed, ErrMaximumDepthExceeded)
+	}
+
+	f, err := m.ListFiles(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileList, 0, len(f.FileList))
+	for _, file := range f.FileList {
+		files = append(files, file)
+		if file.Type == FileTypeFolder && file.Name != path {
+			children, err := m.walkFiles(ctx, file.Name, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, children...)
+		}
+	}
+	return files, nil
+}
+
+// StorageUsage summarizes the storage usage reported alongside a file list, per storage type
+func StorageUsage(files []FileList) []Storage {
+	var storage []Storage
+	seen := make(map[StorageType]struct{})
+	for _, file := range files {
+		if file.TotalSize == 0 {
+			continue
+		}
+		if _, ok := seen[file.StorageType]; ok {
+			continue
+		}
+		seen[file.StorageType] = struct{}{}
+		storage = append(storage, Storage{
+			StorageType: file.StorageType,
+			UsedSize:    file.UsedSize,
+			TotalSize:   file.TotalSize,
+		})
+	}
+	return storage
+}
+
+// DeleteFiles deletes the given files and folders from the machine. If the machine
+// reports that only some of them could be deleted, the returned error wraps a *DeleteFilesError
+func (m *Machine) DeleteFiles(ctx context.Context, files []Path, folders []Path) (*BatchDeleteFilesResponse, error) {
+	for _, p := range append(append([]Path{}, files...), folders...) {
+		if !p.Valid() {
+			return nil, errors.Join(ErrBatchDeleteFilesFailed, fmt.Errorf("%w: %s", ErrInvalidPath, p))
+		}
+	}
+
+	if files == nil {
+		files = []Path{}
+	}
+	if folders == nil {
+		folders = []Path{}
+	}
+
+	return batchDeleteFilesCommand.send(m, ctx, BatchDeleteFilesRequest{FileList: files, FolderList: folders})
+}
@@ -0,0 +1,130 @@
+package sdcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRetrieveHistoricalTasksFailed = errors.New("retrieve historical tasks failed")
+	ErrRetrieveTaskDetailsFailed     = errors.New("retrieve task details failed")
+)
+
+const (
+	taskDetailsBatchSize = 20
+)
+
+var taskStatusNames = map[TaskStatus]string{
+	TaskStatusOther:       "other",
+	TaskStatusCompleted:   "completed",
+	TaskStatusExceptional: "exceptional",
+	TaskStatusStopped:     "stopped",
+}
+
+func (s TaskStatus) String() string {
+	if name, ok := taskStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// Finished returns true if the task has reached a terminal state and its details will no longer change
+func (s TaskStatus) Finished() bool {
+	return s == TaskStatusCompleted || s == TaskStatusExceptional || s == TaskStatusStopped
+}
+
+var taskErrorDescriptions = map[TaskError]string{
+	TaskErrorOk:                    "normal",
+	TaskErrorTempError:             "over-temperature",
+	TaskErrorCalibrateFailed:       "strain gauge calibration failed",
+	TaskErrorResinLack:             "resin level low detected",
+	TaskErrorResinOver:             "resin required by the model exceeds the capacity of the resin vat",
+	TaskErrorProbeFail:             "no resin detected",
+	TaskErrorForeignBody:           "foreign object detected",
+	TaskErrorLevelFailed:           "auto-leveling failed",
+	TaskErrorReleaseFailed:         "model detachment detected",
+	TaskErrorSgOffline:             "strain gauge not connected",
+	TaskErrorLcdDetFailed:          "lcd screen connection abnormal",
+	TaskErrorReleaseOvercount:      "cumulative release film usage has reached the maximum value",
+	TaskErrorUdiskRemove:           "usb drive removed",
+	TaskErrorHomeFailedX:           "x-axis motor anomaly",
+	TaskErrorHomeFailedZ:           "z-axis motor anomaly",
+	TaskErrorResinAbnormalHigh:     "resin level exceeds the maximum value",
+	TaskErrorResinAbnormalLow:      "resin level too low",
+	TaskErrorHomeFailed:            "home position calibration failed",
+	TaskErrorPlatFailed:            "model detected on the platform",
+	TaskErrorError:                 "printing exception",
+	TaskErrorMoveAbnormal:          "motor movement abnormality",
+	TaskErrorAicModelNone:          "no model detected",
+	TaskErrorAicModelWarp:          "model warping detected",
+	TaskErrorHomeFailedY:           "y-axis motor anomaly",
+	TaskErrorFileError:             "error file",
+	TaskErrorCameraError:           "camera error",
+	TaskErrorNetworkError:          "network connection error",
+	TaskErrorServerConnectFailed:   "server connection failed",
+	TaskErrorDisconnectApp:         "printer is not bound to an app",
+	TaskErrorCheckAutoResinFeeder:  "check the automatic resin feeder installation",
+	TaskErrorContainerResinLow:     "resin in the container is running low",
+	TaskErrorBottleDisconnect:      "automatic resin feeder disconnected",
+	TaskErrorFeedTimeout:           "automatic resin feeding timed out",
+	TaskErrorTankTempSensorOffline: "resin vat temperature sensor not connected",
+	TaskErrorTankTempSensorError:   "resin vat over-temperature",
+}
+
+func (e TaskError) String() string {
+	if description, ok := taskErrorDescriptions[e]; ok {
+		return description
+	}
+	return fmt.Sprintf("unknown (%d)", int(e))
+}
+
+func (m *Machine) History(ctx context.Context) ([]string, error) {
+	h, err := historicalTasksCommand.send(m, ctx, RetrieveHistoricalTasksRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return h.HistoryData, nil
+}
+
+// TaskDetails retrieves the details of the given tasks, in the same order as ids. Details of
+// finished tasks are cached, so only unfinished or previously unseen tasks are requested from the machine,
+// in batches of at most taskDetailsBatchSize
+func (m *Machine) TaskDetails(ctx context.Context, ids ...string) ([]TaskDetails, error) {
+	details := make(map[string]TaskDetails, len(ids))
+	var missing []string
+	m.tasksMu.RLock()
+	for _, id := range ids {
+		if t, ok := m.tasks[id]; ok {
+			details[id] = t
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	m.tasksMu.RUnlock()
+
+	for start := 0; start < len(missing); start += taskDetailsBatchSize {
+		batch := missing[start:min(start+taskDetailsBatchSize, len(missing))]
+		t, err := taskDetailsCommand.send(m, ctx, RetrieveTaskDetailsRequest{Id: batch})
+		if err != nil {
+			return nil, err
+		}
+
+		m.tasksMu.Lock()
+		for _, d := range t.HistoryDetailList {
+			details[d.TaskId] = d
+			if d.TaskStatus.Finished() {
+				m.tasks[d.TaskId] = d
+			}
+		}
+		m.tasksMu.Unlock()
+	}
+
+	tasks := make([]TaskDetails, 0, len(ids))
+	for _, id := range ids {
+		if d, ok := details[id]; ok {
+			tasks = append(tasks, d)
+		}
+	}
+	return tasks, nil
+}
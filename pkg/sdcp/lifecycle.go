@@ -0,0 +1,84 @@
+package sdcp
+
+import (
+	"slices"
+)
+
+type PrintEventType string
+
+const (
+	PrintEventTypeStarted   PrintEventType = "started"
+	PrintEventTypePaused    PrintEventType = "paused"
+	PrintEventTypeResumed   PrintEventType = "resumed"
+	PrintEventTypeCompleted PrintEventType = "completed"
+	PrintEventTypeStopped   PrintEventType = "stopped" // Stopped by a user or by the machine, the task details record which
+)
+
+// PrintEvent is a change in the lifecycle of a print, derived from successive status pushes
+type PrintEvent struct {
+	Type         PrintEventType
+	TaskID       string
+	Filename     string
+	CurrentLayer int
+	TotalLayer   int
+	Error        PrintInfoError
+}
+
+// lifecycle compares successive statuses of a machine and returns the changes in the lifecycle of its print
+func lifecycle(previous *Status, current *Status) []PrintEvent {
+	if len(previous.CurrentStatus) == 0 {
+		// The first status received from the machine has nothing to be compared to
+		return nil
+	}
+	before, after := previous.PrintInfo, current.PrintInfo
+	event := func(t PrintEventType) PrintEvent {
+		return PrintEvent{
+			Type:         t,
+			TaskID:       after.TaskId,
+			Filename:     after.Filename,
+			CurrentLayer: after.CurrentLayer,
+			TotalLayer:   after.TotalLayer,
+			Error:        after.ErrorNumber,
+		}
+	}
+
+	var events []PrintEvent
+	wasPrinting := slices.Contains(previous.CurrentStatus, MachineStatusPrinting)
+	printing := slices.Contains(current.CurrentStatus, MachineStatusPrinting)
+	changed := after.TaskId != "" && after.TaskId != before.TaskId
+	if printing && (!wasPrinting || changed) && !after.Status.finished() {
+		events = append(events, event(PrintEventTypeStarted))
+	}
+	if changed {
+		// A new task has no previous sub-status to be compared to
+		before = PrintInfo{}
+	}
+
+	switch {
+	case after.Status == before.Status:
+	case after.Status == PrintInfoStatusPaused:
+		events = append(events, event(PrintEventTypePaused))
+	case after.Status.active() && (before.Status == PrintInfoStatusPaused || before.Status == PrintInfoStatusPausing):
+		events = append(events, event(PrintEventTypeResumed))
+	case after.Status == PrintInfoStatusComplete:
+		events = append(events, event(PrintEventTypeCompleted))
+	case after.Status == PrintInfoStatusStopped:
+		events = append(events, event(PrintEventTypeStopped))
+	}
+	return events
+}
+
+// Printing returns true if the machine is printing a layer, so the print can be paused
+func (s *Status) Printing() bool {
+	return s.PrintInfo.Status.active()
+}
+
+// active returns true if the machine is printing a layer
+func (s PrintInfoStatus) active() bool {
+	return s >= PrintInfoStatusHoming && s <= PrintInfoStatusLifting
+}
+
+// finished returns true if the print has ended
+func (s PrintInfoStatus) finished() bool {
+	return s == PrintInfoStatusStopped || s == PrintInfoStatusComplete
+}
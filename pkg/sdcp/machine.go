@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -20,38 +22,58 @@ var (
 	ErrStatusRefreshFailed      = errors.New("status refresh failed")
 	ErrAttributesRefreshFailed  = errors.New("attributes refresh failed")
 	ErrEnableDisableVideoFailed = errors.New("enable/disable video failed")
+	ErrSetTimeLapseFailed       = errors.New("set time-lapse failed")
 )
 
 const (
 	timeout     = 100 * time.Millisecond
 	refreshTime = 15 * time.Second
-	apiPort     = 3030
 	identifier  = "fluxsdcp"
 )
 
 type inflight struct {
 	signal   chan struct{}
-	response *Response[any]
+	response *Response[json.RawMessage]
+	err      error
 }
 
 type Machine struct {
 	logger types.Logger
 	id     string
-	ip     string
+	port   int
+	label  string
+	tags   []string
 
-	url  *url.URL
-	conn *websocket.Conn
+	addressMu sync.RWMutex
+	ip        string
+	url       *url.URL
+	uploadURL *url.URL
+	redial    chan struct{} // Signalled when the address changes so that supervise redials straight away
+
+	connMu sync.RWMutex
+	conn   *websocket.Conn
+	writer *writer
+
+	stateMu sync.RWMutex
+	state   ConnectionState
 
 	inflightMu sync.RWMutex
 	inflight   map[string]*inflight
 
-	statusMu   sync.RWMutex
-	statusCond *sync.Cond
-	status     Status
+	status     *store[Status]
+	attributes *store[Attributes]
+
+	transferMu sync.Mutex
+	transfer   *transfer
+
+	tasksMu sync.RWMutex
+	tasks   map[string]TaskDetails
 
-	attributesMu   sync.RWMutex
-	attributesCond *sync.Cond
-	attributes     Attributes
+	events *broker
+	hooks  hooks
+
+	eventLogMu sync.RWMutex
+	eventLog   []Event
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -61,176 +83,278 @@ type Machine struct {
 	responseTopic   string
 	statusTopic     string
 	attributesTopic string
+	errorTopic      string
+	noticeTopic     string
 }
 
-func newMachine(id string, ip string, logger types.Logger) (*Machine, error) {
+func createMachine(registration Registration, logger types.Logger, hooks hooks) *Machine {
+	id, ip, port := registration.ID, registration.IP, registration.Port
+	if port == 0 {
+		port = APIPort
+	}
 	m := &Machine{
-		logger: logger.SubLogger("machine").With().Str("id", id).Str("ip", ip).Logger(),
-		id:     id,
-		ip:     ip,
-		url: &url.URL{
-			Scheme: "ws",
-			Host:   fmt.Sprintf("%s:%d", ip, apiPort),
-			Path:   "/websocket",
-		},
+		logger:          logger.SubLogger("machine").With().Str("id", id).Logger(),
+		id:              id,
+		ip:              ip,
+		port:            port,
+		label:           registration.Label,
+		tags:            registration.Tags,
+		redial:          make(chan struct{}, 1),
 		inflight:        make(map[string]*inflight),
+		status:          newStore[Status](),
+		attributes:      newStore[Attributes](),
+		tasks:           make(map[string]TaskDetails),
+		events:          newBroker(),
+		hooks:           hooks,
 		requestTopic:    fmt.Sprintf("sdcp/request/%s", id),
 		responseTopic:   fmt.Sprintf("sdcp/response/%s", id),
 		statusTopic:     fmt.Sprintf("sdcp/status/%s", id),
 		attributesTopic: fmt.Sprintf("sdcp/attributes/%s", id),
+		errorTopic:      fmt.Sprintf("sdcp/error/%s", id),
+		noticeTopic:     fmt.Sprintf("sdcp/notice/%s", id),
 	}
 
-	m.statusCond = sync.NewCond(&m.statusMu)
-	m.attributesCond = sync.NewCond(&m.attributesMu)
-
-	var err error
+	m.url, m.uploadURL = endpoints(ip, port)
 	m.ctx, m.cancel = context.WithCancel(context.Background())
-	m.conn, _, err = websocket.DefaultDialer.DialContext(m.ctx, m.url.String(), nil)
+
+	return m
+}
+
+// endpoints returns the URLs of the websocket and upload endpoints of a machine
+func endpoints(ip string, port int) (*url.URL, *url.URL) {
+	host := net.JoinHostPort(ip, strconv.Itoa(port))
+	return &url.URL{Scheme: "ws", Host: host, Path: "/websocket"}, &url.URL{Scheme: "http", Host: host, Path: uploadPath}
+}
+
+// newMachine connects to the machine and waits for its status and attributes,
+// returning an error if the machine cannot be reached
+func newMachine(registration Registration, logger types.Logger, hooks hooks) (*Machine, error) {
+	m := createMachine(registration, logger, hooks)
+	m.setState(ConnectionStateConnecting)
+	conn, err := m.dial()
 	if err != nil {
+		m.cancel()
 		m.logger.Error().Err(err).Msg("failed to connect to machine")
 		return nil, errors.Join(ErrDialFailed, err)
 	}
 
 	m.logger.Info().Msg("connected to machine")
-	m.wg.Add(1)
-	go m.handle()
+	done := m.start(conn)
 
-	_, err = m.StatusRefreshWait(m.ctx)
+	err = m.sync()
 	if err != nil {
 		m.stop()
-		m.logger.Error().Err(err).Msg("failed to refresh status")
-		return nil, errors.Join(ErrStatusRefreshFailed)
-	}
-
-	_, err = m.AttributesRefreshWait(m.ctx)
-	if err != nil {
-		m.stop()
-		m.logger.Error().Err(err).Msg("failed to refresh attributes")
-		return nil, errors.Join(ErrAttributesRefreshFailed)
+		return nil, err
 	}
+	m.setState(ConnectionStateOnline)
 
-	m.wg.Add(1)
+	m.wg.Add(2)
+	go m.supervise(done)
 	go m.refresh()
 
 	return m, nil
 }
 
-func (m *Machine) StatusRefresh(ctx context.Context) (*StatusRefreshResponse, error) {
-	response, err := request(m, CommandStatusRefresh, StatusRefreshRequest{}, ctx)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error during status refresh request")
-		return nil, errors.Join(ErrStatusRefreshFailed, err)
+// restoreMachine creates a machine that is connected to in the background, so
+// that machines which are currently offline can still be restored from a Registry
+func restoreMachine(registration Registration, logger types.Logger, hooks hooks) *Machine {
+	m := createMachine(registration, logger, hooks)
+	m.setState(ConnectionStateOffline)
+
+	done := make(chan struct{})
+	close(done)
+
+	m.wg.Add(2)
+	go m.supervise(done)
+	go m.refresh()
+
+	return m
+}
+
+func (m *Machine) ID() string {
+	return m.id
+}
+
+func (m *Machine) IP() string {
+	m.addressMu.RLock()
+	defer m.addressMu.RUnlock()
+	return m.ip
+}
+
+// setAddress changes the IP address of the machine, returning the previous address and whether it
+// changed. Any connection to the previous address is closed so that the machine is redialled at the
+// new address straight away.
+func (m *Machine) setAddress(ip string) (string, bool) {
+	m.addressMu.Lock()
+	previous := m.ip
+	if previous == ip {
+		m.addressMu.Unlock()
+		return previous, false
 	}
+	m.ip = ip
+	m.url, m.uploadURL = endpoints(ip, m.port)
+	m.addressMu.Unlock()
 
-	var s StatusRefreshResponse
-	data, err := json.Marshal(response.Data.Data)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error encoding status refresh response")
-		return nil, errors.Join(ErrStatusRefreshFailed, err)
+	select {
+	case m.redial <- struct{}{}:
+	default:
 	}
-	err = json.Unmarshal(data, &s)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error decoding status refresh response")
-		return nil, errors.Join(ErrStatusRefreshFailed, err)
+	m.connMu.RLock()
+	conn := m.conn
+	m.connMu.RUnlock()
+	if conn != nil {
+		_ = conn.Close()
 	}
-	return &s, nil
+	return previous, true
+}
+
+func (m *Machine) Port() int {
+	return m.port
 }
 
+func (m *Machine) Label() string {
+	return m.label
+}
+
+func (m *Machine) Tags() []string {
+	return append([]string(nil), m.tags...)
+}
+
+func (m *Machine) registration() Registration {
+	return Registration{
+		ID:    m.id,
+		IP:    m.IP(),
+		Port:  m.port,
+		Label: m.label,
+		Tags:  m.Tags(),
+	}
+}
+
+func (m *Machine) StatusRefresh(ctx context.Context) (*StatusRefreshResponse, error) {
+	return statusRefreshCommand.send(m, ctx, StatusRefreshRequest{})
+}
+
+// StatusRefreshWait requests a status refresh and waits for the machine to push a status newer than
+// the one held when the request was sent
 func (m *Machine) StatusRefreshWait(ctx context.Context) (*Status, error) {
-	m.statusMu.Lock()
+	_, version := m.StatusSnapshot()
 	_, err := m.StatusRefresh(ctx)
 	if err != nil {
-		m.statusMu.Unlock()
 		return nil, err
 	}
-	m.statusCond.Wait()
-	s := m.status
-	m.statusMu.Unlock()
-	return &s, nil
+	ctx, cancel := m.pushContext(ctx, CommandStatusRefresh)
+	defer cancel()
+	s, _, err := m.WaitStatus(ctx, version)
+	if err != nil {
+		return nil, errors.Join(ErrStatusRefreshFailed, err)
+	}
+	return s, nil
 }
 
 func (m *Machine) Status() *Status {
-	m.statusMu.RLock()
-	s := m.status
-	m.statusMu.RUnlock()
-	return &s
+	s, _ := m.StatusSnapshot()
+	return s
 }
 
-func (m *Machine) AttributesRefresh(ctx context.Context) (*AttributesRefreshResponse, error) {
-	response, err := request(m, CommandAttributesRefresh, AttributesRefreshRequest{}, ctx)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error during attributes refresh request")
-		return nil, errors.Join(ErrAttributesRefreshFailed, err)
-	}
+// StatusSnapshot returns the latest status pushed by the machine and its version, which is incremented on every push
+func (m *Machine) StatusSnapshot() (*Status, uint64) {
+	s, version := m.status.load()
+	return &s, version
+}
 
-	var a AttributesRefreshResponse
-	data, err := json.Marshal(response.Data.Data)
+// WaitStatus waits for the machine to push a status with a version newer than version,
+// failing with ErrDisconnected if the connection is lost first
+func (m *Machine) WaitStatus(ctx context.Context, version uint64) (*Status, uint64, error) {
+	s, version, err := m.status.wait(ctx, version, m.checkConnected)
 	if err != nil {
-		m.logger.Error().Err(err).Msg("error encoding attributes refresh response")
-		return nil, errors.Join(ErrAttributesRefreshFailed, err)
+		return nil, version, err
 	}
-	err = json.Unmarshal(data, &a)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error decoding attributes refresh response")
-		return nil, errors.Join(ErrAttributesRefreshFailed, err)
-	}
-	return &a, nil
+	return &s, version, nil
+}
+
+func (m *Machine) AttributesRefresh(ctx context.Context) (*AttributesRefreshResponse, error) {
+	return attributesRefreshCommand.send(m, ctx, AttributesRefreshRequest{})
 }
 
+// AttributesRefreshWait requests an attributes refresh and waits for the machine to push attributes
+// newer than those held when the request was sent
 func (m *Machine) AttributesRefreshWait(ctx context.Context) (*Attributes, error) {
-	m.attributesMu.Lock()
+	_, version := m.AttributesSnapshot()
 	_, err := m.AttributesRefresh(ctx)
 	if err != nil {
-		m.attributesMu.Unlock()
 		return nil, err
 	}
-	m.attributesCond.Wait()
-	a := m.attributes
-	m.attributesMu.Unlock()
-	return &a, nil
+	ctx, cancel := m.pushContext(ctx, CommandAttributesRefresh)
+	defer cancel()
+	a, _, err := m.WaitAttributes(ctx, version)
+	if err != nil {
+		return nil, errors.Join(ErrAttributesRefreshFailed, err)
+	}
+	return a, nil
 }
 
 func (m *Machine) Attributes() *Attributes {
-	m.attributesMu.RLock()
-	a := m.attributes
-	m.attributesMu.RUnlock()
-	return &a
+	a, _ := m.AttributesSnapshot()
+	return a
 }
 
-func (m *Machine) EnableDisableVideo(ctx context.Context, enable bool) (*EnableDisableVideoStreamResponse, error) {
-	_enable := EnableDisableDisable
-	if enable {
-		_enable = EnableDisableEnable
-	}
+// AttributesSnapshot returns the latest attributes pushed by the machine and their version, which is incremented on every push
+func (m *Machine) AttributesSnapshot() (*Attributes, uint64) {
+	a, version := m.attributes.load()
+	return &a, version
+}
 
-	response, err := request(m, CommandEnableDisableVideoStream, EnableDisableVideoStreamRequest{Enable: _enable}, ctx)
+// WaitAttributes waits for the machine to push attributes with a version newer than version,
+// failing with ErrDisconnected if the connection is lost first
+func (m *Machine) WaitAttributes(ctx context.Context, version uint64) (*Attributes, uint64, error) {
+	a, version, err := m.attributes.wait(ctx, version, m.checkConnected)
 	if err != nil {
-		m.logger.Error().Err(err).Msg("error during status refresh request")
-		return nil, errors.Join(ErrStatusRefreshFailed, err)
+		return nil, version, err
 	}
+	return &a, version, nil
+}
 
-	var a EnableDisableVideoStreamResponse
-	data, err := json.Marshal(response.Data.Data)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error encoding enable/disable video stream response")
-		return nil, errors.Join(ErrEnableDisableVideoFailed, err)
-	}
-	err = json.Unmarshal(data, &a)
-	if err != nil {
-		m.logger.Error().Err(err).Msg("error decoding enable/disable video stream response")
-		return nil, errors.Join(ErrEnableDisableVideoFailed, err)
+// pushContext bounds the time a refresh waits for the machine to push the refreshed value by the timeout
+// of the refresh request, so that a machine which acknowledges a refresh but never pushes cannot block it
+func (m *Machine) pushContext(ctx context.Context, command Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, m.policy(command).Timeout, ErrTimeout)
+}
+
+func (m *Machine) EnableDisableVideo(ctx context.Context, enable bool) (*EnableDisableVideoStreamResponse, error) {
+	return videoStreamCommand.send(m, ctx, EnableDisableVideoStreamRequest{Enable: enableDisable(enable)})
+}
+
+// SetTimeLapse enables or disables time-lapse photography of prints
+func (m *Machine) SetTimeLapse(ctx context.Context, enable bool) (*EnableDisableTimeLapseResponse, error) {
+	return timeLapseCommand.send(m, ctx, EnableDisableTimeLapseRequest{Enable: enableDisable(enable)})
+}
+
+func enableDisable(enable bool) EnableDisable {
+	if enable {
+		return EnableDisableEnable
 	}
-	return &a, nil
+	return EnableDisableDisable
 }
 
 func (m *Machine) stop() {
 	m.cancel()
-	_ = m.conn.Close()
+	m.connMu.Lock()
+	if m.conn != nil {
+		_ = m.conn.Close()
+	}
+	m.connMu.Unlock()
 	m.wg.Wait()
+	m.events.close()
 }
 
-func (m *Machine) handle() {
+func (m *Machine) handle(conn *websocket.Conn, staleTimeout time.Duration, done chan struct{}) {
 	defer m.wg.Done()
+	defer close(done)
+	defer m.disconnected(conn)
+	// Every frame, including the pongs answering the pings sent by the writer, shows that the connection is alive
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(staleTimeout))
+	})
 	var topicMessage TopicMessage
 	var err error
 	var message []byte
@@ -239,9 +363,17 @@ func (m *Machine) handle() {
 		case <-m.ctx.Done():
 			return
 		default:
-			_, message, err = m.conn.ReadMessage()
+			_ = conn.SetReadDeadline(time.Now().Add(staleTimeout))
+			_, message, err = conn.ReadMessage()
 			if err != nil {
-				m.logger.Error().Err(err).Msg("error reading from websocket")
+				var netErr net.Error
+				switch {
+				case m.ctx.Err() != nil:
+				case errors.As(err, &netErr) && netErr.Timeout():
+					m.logger.Warn().Str("timeout", staleTimeout.String()).Msg("connection stale, no frames received")
+				default:
+					m.logger.Error().Err(err).Msg("error reading from websocket")
+				}
 				return
 			}
 			err = json.Unmarshal(message, &topicMessage)
@@ -253,15 +385,16 @@ func (m *Machine) handle() {
 			m.logger.Debug().Str("topic", topicMessage.Topic).Msg("received message")
 			switch topicMessage.Topic {
 			case m.responseTopic:
-				var response Response[any]
+				var response Response[json.RawMessage]
 				err = json.Unmarshal(message, &response)
 				if err != nil {
 					m.logger.Error().Err(err).Msg("error decoding response message")
 					continue
 				}
-				m.inflightMu.RLock()
+				m.inflightMu.Lock()
 				i, ok := m.inflight[response.Data.RequestID]
-				m.inflightMu.RUnlock()
+				delete(m.inflight, response.Data.RequestID)
+				m.inflightMu.Unlock()
 				if ok {
 					i.response = &response
 					close(i.signal)
@@ -276,10 +409,12 @@ func (m *Machine) handle() {
 					m.logger.Error().Err(err).Msg("error decoding status message")
 					continue
 				}
-				m.statusMu.Lock()
-				m.status = status.Status
-				m.statusCond.Broadcast()
-				m.statusMu.Unlock()
+				previous, _ := m.status.set(status.Status)
+				m.publish(Event{Type: EventTypeStatus, Status: &status.Status})
+				for _, e := range lifecycle(&previous, &status.Status) {
+					m.publish(Event{Type: EventTypePrint, Print: &e})
+					m.logger.Info().Str("task", e.TaskID).Str("filename", e.Filename).Msgf("print %s", e.Type)
+				}
 				m.logger.Debug().Msgf("received status update")
 			case m.attributesTopic:
 				var attributes AttributesMessage
@@ -288,11 +423,27 @@ func (m *Machine) handle() {
 					m.logger.Error().Err(err).Msg("error decoding attributes message")
 					continue
 				}
-				m.attributesMu.Lock()
-				m.attributes = attributes.Attributes
-				m.attributesCond.Broadcast()
-				m.attributesMu.Unlock()
+				m.attributes.set(attributes.Attributes)
+				m.publish(Event{Type: EventTypeAttributes, Attributes: &attributes.Attributes})
 				m.logger.Debug().Msgf("received attributes update")
+			case m.errorTopic:
+				var e Error
+				err = json.Unmarshal(message, &e)
+				if err != nil {
+					m.logger.Error().Err(err).Msg("error decoding error message")
+					continue
+				}
+				m.record(Event{Type: EventTypeError, Error: &e.Data})
+				m.logger.Warn().Str("error", e.Data.Data.ErrorCode.String()).Msg("received error")
+			case m.noticeTopic:
+				var notification Notification
+				err = json.Unmarshal(message, &notification)
+				if err != nil {
+					m.logger.Error().Err(err).Msg("error decoding notice message")
+					continue
+				}
+				m.record(Event{Type: EventTypeNotice, Notice: &notification.Data})
+				m.logger.Info().Str("notice", notification.Data.Data.Type.String()).Msg("received notice")
 			default:
 				m.logger.Warn().Str("topic", topicMessage.Topic).Msg("unknown topic")
 			}
@@ -308,6 +459,9 @@ func (m *Machine) refresh() {
 		case <-m.ctx.Done():
 			return
 		case <-time.After(refreshTime):
+			if m.State() != ConnectionStateOnline {
+				continue
+			}
 			_, err = m.StatusRefresh(m.ctx)
 			if err != nil {
 				m.logger.Error().Err(err).Msg("error refreshing status")
@@ -320,7 +474,41 @@ func (m *Machine) refresh() {
 	}
 }
 
-func request[T any](m *Machine, command Command, request T, ctx context.Context) (*Response[any], error) {
+func request[T any](m *Machine, command Command, request T, ctx context.Context) (response *Response[json.RawMessage], err error) {
+	if m.hooks.observe != nil {
+		start := time.Now()
+		defer func() {
+			m.hooks.observe(command, time.Since(start), err)
+		}()
+	}
+
+	p := m.policy(command)
+	for attempt := 1; ; attempt++ {
+		response, err = send(m, command, request, ctx, p.Timeout)
+		var timeoutErr *TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			return response, err
+		}
+		timeoutErr.Attempts = attempt
+		if attempt >= p.Attempts {
+			m.logger.Warn().Str("command", command.String()).Int("attempts", attempt).Msg("request timed out")
+			return nil, err
+		}
+		m.logger.Debug().Str("command", command.String()).Int("attempt", attempt).Msg("request timed out, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-m.ctx.Done():
+			return nil, m.ctx.Err()
+		case <-time.After(p.backoff(attempt + 1)):
+		}
+	}
+}
+
+// send sends a single request and waits up to timeout for its response. The request fails
+// immediately if the connection to the machine is lost while it is waiting.
+func send[T any](m *Machine, command Command, request T, ctx context.Context, timeout time.Duration) (*Response[json.RawMessage], error) {
 	requestID := uuid.New().String()
 	msg := &Request[T]{
 		TopicMessage: TopicMessage{
@@ -337,9 +525,10 @@ func request[T any](m *Machine, command Command, request T, ctx context.Context)
 		},
 	}
 
+	// The request is in flight before the connection is read, so that it is either
+	// failed by disconnected or sees that there is no connection
 	i := &inflight{
-		signal:   make(chan struct{}),
-		response: new(Response[any]),
+		signal: make(chan struct{}),
 	}
 	m.inflightMu.Lock()
 	m.inflight[requestID] = i
@@ -350,18 +539,32 @@ func request[T any](m *Machine, command Command, request T, ctx context.Context)
 		m.inflightMu.Unlock()
 	}()
 
-	err := m.conn.WriteJSON(msg)
+	m.connMu.RLock()
+	w := m.writer
+	m.connMu.RUnlock()
+	if w == nil {
+		return nil, ErrNotConnected
+	}
+
+	err := w.send(ctx, command.priority(), msg)
 	if err != nil {
 		return nil, err
 	}
 
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-m.ctx.Done():
 		return nil, m.ctx.Err()
+	case <-timer.C:
+		return nil, &TimeoutError{Command: command, Timeout: timeout}
 	case <-i.signal:
 	}
 
+	if i.err != nil {
+		return nil, i.err
+	}
 	return i.response, nil
 }
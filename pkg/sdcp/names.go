@@ -0,0 +1,65 @@
+package sdcp
+
+import "fmt"
+
+var machineStatusNames = map[MachineStatus]string{
+	MachineStatusIdle:             "idle",
+	MachineStatusPrinting:         "printing",
+	MachineStatusFileTransferring: "file_transferring",
+	MachineStatusExposureTesting:  "exposure_testing",
+	MachineStatusDevicesTesting:   "devices_testing",
+}
+
+func (s MachineStatus) String() string {
+	if name, ok := machineStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+var printInfoStatusNames = map[PrintInfoStatus]string{
+	PrintInfoStatusIdle:         "idle",
+	PrintInfoStatusHoming:       "homing",
+	PrintInfoStatusDropping:     "dropping",
+	PrintInfoStatusExposing:     "exposing",
+	PrintInfoStatusLifting:      "lifting",
+	PrintInfoStatusPausing:      "pausing",
+	PrintInfoStatusPaused:       "paused",
+	PrintInfoStatusStopping:     "stopping",
+	PrintInfoStatusStopped:      "stopped",
+	PrintInfoStatusComplete:     "complete",
+	PrintInfoStatusFileChecking: "file_checking",
+}
+
+func (s PrintInfoStatus) String() string {
+	if name, ok := printInfoStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+var commandNames = map[Command]string{
+	CommandStatusRefresh:            "status_refresh",
+	CommandAttributesRefresh:        "attributes_refresh",
+	CommandStartPrint:               "start_print",
+	CommandPausePrint:               "pause_print",
+	CommandStopPrint:                "stop_print",
+	CommandResumePrint:              "resume_print",
+	CommandStopFeedingMaterial:      "stop_feeding_material",
+	CommandSkipPreheating:           "skip_preheating",
+	CommandChangePrinterName:        "change_printer_name",
+	CommandTerminateFileTransfer:    "terminate_file_transfer",
+	CommandRetrieveFileList:         "retrieve_file_list",
+	CommandBatchDeleteFiles:         "batch_delete_files",
+	CommandRetrieveHistoricalTasks:  "retrieve_historical_tasks",
+	CommandRetrieveTaskDetails:      "retrieve_task_details",
+	CommandEnableDisableVideoStream: "enable_disable_video_stream",
+	CommandEnableDisableTimeLapse:   "enable_disable_time_lapse",
+}
+
+func (c Command) String() string {
+	if name, ok := commandNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(c))
+}
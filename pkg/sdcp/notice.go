@@ -0,0 +1,54 @@
+package sdcp
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	maximumEventLogSize = 100
+)
+
+var errorCodeDescriptions = map[ErrorCode]string{
+	ErrorCodeMD5Failed:    "file transfer md5 check failed",
+	ErrorCodeFormatFailed: "file format is incorrect",
+}
+
+func (c ErrorCode) String() string {
+	if description, ok := errorCodeDescriptions[c]; ok {
+		return description
+	}
+	return fmt.Sprintf("unknown (%d)", int(c))
+}
+
+var notificationTypeDescriptions = map[NotificationType]string{
+	HistorySynchronizationSuccessful: "history synchronization successful",
+}
+
+func (t NotificationType) String() string {
+	if description, ok := notificationTypeDescriptions[t]; ok {
+		return description
+	}
+	return fmt.Sprintf("unknown (%d)", int(t))
+}
+
+// EventLog returns the most recent error and notice events received from the machine, oldest first
+func (m *Machine) EventLog() []Event {
+	m.eventLogMu.RLock()
+	defer m.eventLogMu.RUnlock()
+	return append([]Event(nil), m.eventLog...)
+}
+
+// record publishes the event and appends it to the event log, discarding the
+// oldest events once the log holds more than maximumEventLogSize events
+func (m *Machine) record(e Event) {
+	e.MachineID = m.id
+	e.Time = time.Now()
+	m.eventLogMu.Lock()
+	if len(m.eventLog) >= maximumEventLogSize {
+		m.eventLog = append(m.eventLog[:0], m.eventLog[len(m.eventLog)-maximumEventLogSize+1:]...)
+	}
+	m.eventLog = append(m.eventLog, e)
+	m.eventLogMu.Unlock()
+	m.publish(e)
+}
@@ -0,0 +1,76 @@
+package sdcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorsAndNotices(t *testing.T) {
+	const id = "0123456789abcdef"
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		_ = conn.WriteJSON(&Error{
+			TopicMessage: TopicMessage{Topic: "sdcp/error/" + id},
+			Data:         ErrorData{Data: ErrorCodeData{ErrorCode: ErrorCodeMD5Failed}, MainboardID: id},
+		})
+		_ = conn.WriteJSON(&Notification{
+			TopicMessage: TopicMessage{Topic: "sdcp/notice/" + id},
+			Data:         NotificationData{Data: NotificationTypeData{Message: "synced", Type: HistorySynchronizationSuccessful}, MainboardID: id},
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	events := make(chan Event, 8)
+	m := createMachine(Registration{ID: id, IP: "127.0.0.1"}, logging.Test(t, logging.Slog, t.Name()), hooks{publish: func(e Event) {
+		events <- e
+	}})
+	m.url.Host = server.Listener.Addr().String()
+	conn, err := m.dial()
+	require.NoError(t, err)
+	m.start(conn)
+	t.Cleanup(m.stop)
+
+	next := func() Event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return Event{}
+		}
+	}
+
+	// Messages on the error and notice topics are published as events and kept in the event log
+	e := next()
+	require.Equal(t, EventTypeError, e.Type)
+	require.Equal(t, id, e.MachineID)
+	require.Equal(t, ErrorCodeMD5Failed, e.Error.Data.ErrorCode)
+	require.Equal(t, "file transfer md5 check failed", e.Error.Data.ErrorCode.String())
+	e = next()
+	require.Equal(t, EventTypeNotice, e.Type)
+	require.Equal(t, HistorySynchronizationSuccessful, e.Notice.Data.Type)
+	require.Equal(t, "synced", e.Notice.Data.Message)
+
+	log := m.EventLog()
+	require.Len(t, log, 2)
+	require.Equal(t, EventTypeError, log[0].Type)
+	require.Equal(t, EventTypeNotice, log[1].Type)
+}
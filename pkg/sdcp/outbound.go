@@ -0,0 +1,176 @@
+package sdcp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrQueueFull = errors.New("outbound queue full")
+)
+
+const (
+	DefaultQueueSize    = 64
+	DefaultWriteTimeout = 5 * time.Second
+	DefaultPingInterval = 15 * time.Second
+	DefaultStaleTimeout = time.Minute
+)
+
+// ConnectionOptions configures the websocket connection to every machine
+type ConnectionOptions struct {
+	QueueSize    int           // Messages of each priority that are queued before requests are rejected with ErrQueueFull
+	WriteTimeout time.Duration // Time a single message may take to be written
+	PingInterval time.Duration // Time between pings, which keep the connection alive when no requests are sent
+	StaleTimeout time.Duration // The connection is closed and redialed if no frames are received for this long
+}
+
+func (o *ConnectionOptions) defaults() {
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultQueueSize
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = DefaultWriteTimeout
+	}
+	if o.PingInterval <= 0 {
+		o.PingInterval = DefaultPingInterval
+	}
+	if o.StaleTimeout <= 0 {
+		o.StaleTimeout = DefaultStaleTimeout
+	}
+}
+
+// SetConnectionOptions configures the connections to every machine, taking effect the next time each machine connects
+func (s *SDCP) SetConnectionOptions(options ConnectionOptions) {
+	options.defaults()
+	s.connection.Store(&options)
+}
+
+// ConnectionOptions returns the options connections to machines are made with
+func (s *SDCP) ConnectionOptions() ConnectionOptions {
+	if options := s.connection.Load(); options != nil {
+		return *options
+	}
+	var options ConnectionOptions
+	options.defaults()
+	return options
+}
+
+type priority int
+
+const (
+	priorityNormal  priority = iota // Polling and every other request
+	priorityControl                 // Requests that stop the machine, which must not wait behind polling traffic
+)
+
+func (c Command) priority() priority {
+	switch c {
+	case CommandStopPrint, CommandPausePrint, CommandStopFeedingMaterial:
+		return priorityControl
+	default:
+		return priorityNormal
+	}
+}
+
+type outbound struct {
+	message any
+	result  chan error
+}
+
+// writer queues messages for the single goroutine that writes to a connection, as
+// websocket connections support only one concurrent writer
+type writer struct {
+	control chan outbound
+	normal  chan outbound
+	done    chan struct{} // Closed once the writer stops writing to the connection
+}
+
+func newWriter(size int) *writer {
+	return &writer{
+		control: make(chan outbound, size),
+		normal:  make(chan outbound, size),
+		done:    make(chan struct{}),
+	}
+}
+
+// send queues message and waits for it to be written
+func (w *writer) send(ctx context.Context, p priority, message any) error {
+	o := outbound{
+		message: message,
+		result:  make(chan error, 1),
+	}
+	queue := w.normal
+	if p == priorityControl {
+		queue = w.control
+	}
+	select {
+	case <-w.done:
+		return ErrDisconnected
+	case queue <- o:
+	default:
+		return ErrQueueFull
+	}
+
+	select {
+	case err := <-o.result:
+		return err
+	case <-w.done:
+		select {
+		case err := <-o.result:
+			return err
+		default:
+			return ErrDisconnected
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// write writes queued messages to conn, control messages first, and pings the machine whenever
+// nothing has been written for an interval. A failed write closes conn, which makes the read loop
+// fail every in-flight request.
+func (m *Machine) write(conn *websocket.Conn, w *writer, options ConnectionOptions, done <-chan struct{}) {
+	defer m.wg.Done()
+	defer close(w.done)
+	ping := time.NewTicker(options.PingInterval)
+	defer ping.Stop()
+
+	for {
+		var o outbound
+		select {
+		case o = <-w.control:
+		default:
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-done:
+				return
+			case o = <-w.control:
+			case o = <-w.normal:
+			case <-ping.C:
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(options.WriteTimeout))
+				if err != nil {
+					m.logger.Warn().Err(err).Msg("failed to ping machine")
+					_ = conn.Close()
+					return
+				}
+				continue
+			}
+		}
+
+		err := conn.SetWriteDeadline(time.Now().Add(options.WriteTimeout))
+		if err == nil {
+			err = conn.WriteJSON(o.message)
+		}
+		if err != nil {
+			o.result <- errors.Join(ErrDisconnected, err)
+			m.logger.Error().Err(err).Msg("error writing to websocket")
+			_ = conn.Close()
+			return
+		}
+		o.result <- nil
+		ping.Reset(options.PingInterval)
+	}
+}
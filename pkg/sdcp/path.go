@@ -1,6 +1,9 @@
 package sdcp
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Path string
 
@@ -11,3 +14,13 @@ func USBPath(p string) Path {
 func LocalPath(p string) Path {
 	return Path(fmt.Sprintf("/local/%s", p))
 }
+
+// Valid returns true if the path is rooted in the local or USB storage of a machine
+func (p Path) Valid() bool {
+	if strings.Contains(string(p), "..") {
+		return false
+	}
+	return p == "/local" || p == "/usb" ||
+		strings.HasPrefix(string(p), string(LocalPath(""))) ||
+		strings.HasPrefix(string(p), string(USBPath("")))
+}
@@ -0,0 +1,97 @@
+package sdcp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTimeout = errors.New("request timed out")
+)
+
+// RequestPolicy configures how long a request for a command waits for a response, and how many
+// times it is sent before giving up. Only commands that are safe to repeat should be retried.
+type RequestPolicy struct {
+	Timeout  time.Duration // Time a single attempt waits for a response
+	Attempts int           // Number of times the request is sent before a TimeoutError is returned
+	Backoff  time.Duration // Time waited before the second attempt, doubling after every attempt
+}
+
+// DefaultRequestPolicy is used for commands that have no policy
+var DefaultRequestPolicy = RequestPolicy{Timeout: 10 * time.Second, Attempts: 1}
+
+// DefaultRequestPolicies are the policies of every command. Commands that only retrieve information
+// are retried, while commands that control the machine are sent once.
+var DefaultRequestPolicies = map[Command]RequestPolicy{
+	CommandStatusRefresh:            {Timeout: 5 * time.Second, Attempts: 3, Backoff: 500 * time.Millisecond},
+	CommandAttributesRefresh:        {Timeout: 5 * time.Second, Attempts: 3, Backoff: 500 * time.Millisecond},
+	CommandStartPrint:               {Timeout: 30 * time.Second, Attempts: 1}, // The machine checks the file before answering
+	CommandPausePrint:               DefaultRequestPolicy,
+	CommandStopPrint:                DefaultRequestPolicy,
+	CommandResumePrint:              DefaultRequestPolicy,
+	CommandStopFeedingMaterial:      DefaultRequestPolicy,
+	CommandSkipPreheating:           DefaultRequestPolicy,
+	CommandChangePrinterName:        DefaultRequestPolicy,
+	CommandTerminateFileTransfer:    DefaultRequestPolicy,
+	CommandRetrieveFileList:         {Timeout: 10 * time.Second, Attempts: 3, Backoff: 500 * time.Millisecond},
+	CommandBatchDeleteFiles:         DefaultRequestPolicy,
+	CommandRetrieveHistoricalTasks:  {Timeout: 10 * time.Second, Attempts: 3, Backoff: 500 * time.Millisecond},
+	CommandRetrieveTaskDetails:      {Timeout: 10 * time.Second, Attempts: 3, Backoff: 500 * time.Millisecond},
+	CommandEnableDisableVideoStream: DefaultRequestPolicy,
+	CommandEnableDisableTimeLapse:   DefaultRequestPolicy,
+}
+
+// TimeoutError is returned when a machine does not answer any attempt of a request before its timeout
+type TimeoutError struct {
+	Command  Command
+	Timeout  time.Duration
+	Attempts int
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s request timed out after %d attempts of %s", e.Command, e.Attempts, e.Timeout)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// SetRequestPolicy sets the policy of requests for command sent to every machine
+func (s *SDCP) SetRequestPolicy(command Command, policy RequestPolicy) {
+	s.policiesMu.Lock()
+	s.policies[command] = policy
+	s.policiesMu.Unlock()
+}
+
+// RequestPolicy returns the policy of requests for command
+func (s *SDCP) RequestPolicy(command Command) RequestPolicy {
+	s.policiesMu.RLock()
+	defer s.policiesMu.RUnlock()
+	return lookupPolicy(s.policies, command)
+}
+
+func lookupPolicy(policies map[Command]RequestPolicy, command Command) RequestPolicy {
+	p, ok := policies[command]
+	if !ok {
+		p = DefaultRequestPolicy
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = DefaultRequestPolicy.Timeout
+	}
+	p.Attempts = max(p.Attempts, 1)
+	return p
+}
+
+// policy returns the policy of requests for command sent to the machine
+func (m *Machine) policy(command Command) RequestPolicy {
+	if m.hooks.policy != nil {
+		return m.hooks.policy(command)
+	}
+	return lookupPolicy(DefaultRequestPolicies, command)
+}
+
+// backoff returns the time waited before the given attempt, starting from 1, of a request
+func (p RequestPolicy) backoff(attempt int) time.Duration {
+	return p.Backoff << min(attempt-2, 16)
+}
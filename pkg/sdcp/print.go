@@ -0,0 +1,75 @@
+package sdcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrStartPrintFailed          = errors.New("start print failed")
+	ErrPausePrintFailed          = errors.New("pause print failed")
+	ErrStopPrintFailed           = errors.New("stop print failed")
+	ErrResumePrintFailed         = errors.New("resume print failed")
+	ErrStopFeedingMaterialFailed = errors.New("stop feeding material failed")
+	ErrSkipPreheatingFailed      = errors.New("skip preheating failed")
+)
+
+var (
+	ErrControlBusy              = errors.New("machine is busy")
+	ErrControlFileNotFound      = errors.New("file not found")
+	ErrControlMD5Failed         = errors.New("file md5 verification failed")
+	ErrControlFileIOFailed      = errors.New("file read failed")
+	ErrControlInvalidResolution = errors.New("file resolution does not match machine")
+	ErrControlUnknownFormat     = errors.New("unrecognized file format")
+	ErrControlUnknownModel      = errors.New("file machine model does not match machine")
+	ErrControlUnknownAck        = errors.New("unknown control acknowledgement")
+)
+
+// Err returns the error associated with the ControlAck, or nil if the ControlAck is ControlAckOk
+func (a ControlAck) Err() error {
+	switch a {
+	case ControlAckOk:
+		return nil
+	case ControlAckBusy:
+		return ErrControlBusy
+	case ControlAckNotFound:
+		return ErrControlFileNotFound
+	case ControlAckMd5FailFailed:
+		return ErrControlMD5Failed
+	case ControlAckFileIOFailed:
+		return ErrControlFileIOFailed
+	case ControlAckInvalidResolution:
+		return ErrControlInvalidResolution
+	case ControlAckUnknownFormat:
+		return ErrControlUnknownFormat
+	case ControlAckUnknownModel:
+		return ErrControlUnknownModel
+	default:
+		return fmt.Errorf("%w: %d", ErrControlUnknownAck, a)
+	}
+}
+
+func (m *Machine) StartPrint(ctx context.Context, filename string, startLayer int) (*StartPrintingResponse, error) {
+	return startPrintCommand.send(m, ctx, StartPrintingRequest{Filename: filename, StartLayer: startLayer})
+}
+
+func (m *Machine) PausePrint(ctx context.Context) (*PausePrintingResponse, error) {
+	return pausePrintCommand.send(m, ctx, PausePrintingRequest{})
+}
+
+func (m *Machine) StopPrint(ctx context.Context) (*StopPrintingResponse, error) {
+	return stopPrintCommand.send(m, ctx, StopPrintingRequest{})
+}
+
+func (m *Machine) ResumePrint(ctx context.Context) (*ResumePrintingResponse, error) {
+	return resumePrintCommand.send(m, ctx, ResumePrintingRequest{})
+}
+
+func (m *Machine) StopFeedingMaterial(ctx context.Context) (*StopFeedingMaterialResponse, error) {
+	return stopFeedingMaterialCommand.send(m, ctx, StopFeedingMaterialRequest{})
+}
+
+func (m *Machine) SkipPreheating(ctx context.Context) (*SkipPreheatingResponse, error) {
+	return skipPreheatingCommand.send(m, ctx, SkipPreheatingRequest{})
+}
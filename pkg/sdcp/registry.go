@@ -0,0 +1,22 @@
+package sdcp
+
+// Registration is the information required to register a machine
+type Registration struct {
+	ID    string   `json:"id"`             // Motherboard ID (16-bit)
+	IP    string   `json:"ip"`             // Motherboard IP Address
+	Port  int      `json:"port,omitempty"` // Port of the SDCP and file upload endpoints, defaults to APIPort
+	Label string   `json:"label"`          // Friendly label for the machine
+	Tags  []string `json:"tags"`           // Tags used to group machines
+}
+
+// Registry persists the registered machines so that they can be restored when Flux restarts
+type Registry interface {
+	// List returns every persisted registration
+	List() ([]Registration, error)
+
+	// Put persists a registration, replacing any existing registration with the same ID
+	Put(registration Registration) error
+
+	// Delete removes the registration with the given ID, if it exists
+	Delete(id string) error
+}
@@ -2,54 +2,128 @@ package sdcp
 
 import (
 	"errors"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/loopholelabs/logging/types"
 )
 
 var (
 	ErrAlreadyRegistered = errors.New("machine already registered")
+	ErrNotRegistered     = errors.New("machine not registered")
 	ErrRegisterFailed    = errors.New("failed to register machine")
+	ErrRestoreFailed     = errors.New("failed to restore machines")
 )
 
+// RequestObserver is called with the duration and result of every request sent to a machine
+type RequestObserver func(machineID string, command Command, duration time.Duration, err error)
+
+// hooks are provided by SDCP to every machine it manages
+type hooks struct {
+	publish    func(Event)
+	observe    func(Command, time.Duration, error)
+	policy     func(Command) RequestPolicy
+	connection func() ConnectionOptions
+}
+
 type SDCP struct {
-	logger types.Logger
+	logger     types.Logger
+	registry   Registry
+	events     *broker
+	observer   atomic.Pointer[RequestObserver]
+	connection atomic.Pointer[ConnectionOptions]
+
+	policiesMu sync.RWMutex
+	policies   map[Command]RequestPolicy
 
 	machinesMu sync.RWMutex
 	machines   map[string]*Machine
 }
 
-func New(logger types.Logger) *SDCP {
+// New creates a new SDCP instance. If registry is not nil, registered machines are persisted to it
+func New(logger types.Logger, registry Registry) *SDCP {
 	return &SDCP{
 		logger:   logger.SubLogger("sdcp"),
+		registry: registry,
+		events:   newBroker(),
+		policies: maps.Clone(DefaultRequestPolicies),
 		machines: make(map[string]*Machine),
 	}
 }
 
-func (s *SDCP) Register(machineID string, machineIP string) error {
+// Restore registers every machine persisted in the registry. Machines that are
+// currently unreachable are registered anyway and connected to in the background
+func (s *SDCP) Restore() error {
+	if s.registry == nil {
+		return nil
+	}
+
+	registrations, err := s.registry.List()
+	if err != nil {
+		return errors.Join(ErrRestoreFailed, err)
+	}
+
 	s.machinesMu.Lock()
-	if _, ok := s.machines[machineID]; ok {
-		s.machinesMu.Unlock()
+	for _, r := range registrations {
+		if _, ok := s.machines[r.ID]; ok {
+			continue
+		}
+		s.machines[r.ID] = restoreMachine(r, s.logger, s.hooks(r.ID))
+		s.logger.Info().Str("id", r.ID).Str("ip", r.IP).Msg("restored machine")
+	}
+	s.machinesMu.Unlock()
+	return nil
+}
+
+// Register connects to the machine and registers it once its status and attributes have been synced.
+// The machine is connected to without holding the registry lock, so concurrent registrations of the
+// same machine are only rejected once one of them has connected.
+func (s *SDCP) Register(registration Registration) error {
+	if _, ok := s.GetMachine(registration.ID); ok {
 		return ErrAlreadyRegistered
 	}
-	m, err := newMachine(machineID, machineIP, s.logger)
+	m, err := newMachine(registration, s.logger, s.hooks(registration.ID))
 	if err != nil {
-		s.machinesMu.Unlock()
 		return errors.Join(ErrRegisterFailed, err)
 	}
-	s.machines[machineID] = m
+
+	s.machinesMu.Lock()
+	if _, ok := s.machines[registration.ID]; ok {
+		s.machinesMu.Unlock()
+		m.stop()
+		return ErrAlreadyRegistered
+	}
+	s.machines[registration.ID] = m
 	s.machinesMu.Unlock()
+
+	if s.registry != nil {
+		err = s.registry.Put(m.registration())
+		if err != nil {
+			s.logger.Error().Err(err).Str("id", registration.ID).Msg("failed to persist machine registration")
+		}
+	}
 	return nil
 }
 
 func (s *SDCP) Unregister(machineID string) bool {
 	s.machinesMu.Lock()
 	m, ok := s.machines[machineID]
+	delete(s.machines, machineID)
+	s.machinesMu.Unlock()
 	if ok {
 		m.stop()
-		delete(s.machines, machineID)
 	}
-	s.machinesMu.Unlock()
+
+	if ok && s.registry != nil {
+		err := s.registry.Delete(machineID)
+		if err != nil {
+			s.logger.Error().Err(err).Str("id", machineID).Msg("failed to delete persisted machine registration")
+		}
+	}
 	return ok
 }
 
@@ -60,11 +134,48 @@ func (s *SDCP) GetMachine(machineID string) (*Machine, bool) {
 	return m, ok
 }
 
+// ObserveRequests sets the RequestObserver that is called for every request sent to every machine
+func (s *SDCP) ObserveRequests(observer RequestObserver) {
+	s.observer.Store(&observer)
+}
+
+// Machines returns every registered machine
+func (s *SDCP) Machines() []*Machine {
+	s.machinesMu.RLock()
+	machines := make([]*Machine, 0, len(s.machines))
+	for _, m := range s.machines {
+		machines = append(machines, m)
+	}
+	s.machinesMu.RUnlock()
+	slices.SortFunc(machines, func(a, b *Machine) int {
+		return strings.Compare(a.id, b.id)
+	})
+	return machines
+}
+
+func (s *SDCP) hooks(machineID string) hooks {
+	return hooks{
+		publish: s.events.publish,
+		observe: func(command Command, duration time.Duration, err error) {
+			if observer := s.observer.Load(); observer != nil && *observer != nil {
+				(*observer)(machineID, command, duration, err)
+			}
+		},
+		policy:     s.RequestPolicy,
+		connection: s.ConnectionOptions,
+	}
+}
+
 func (s *SDCP) Close() {
 	s.machinesMu.Lock()
+	machines := make([]*Machine, 0, len(s.machines))
 	for _, m := range s.machines {
-		m.stop()
+		machines = append(machines, m)
 	}
 	clear(s.machines)
 	s.machinesMu.Unlock()
+	for _, m := range machines {
+		m.stop()
+	}
+	s.events.close()
 }
@@ -0,0 +1,259 @@
+package sdcptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	ackOk    = 0
+	ackError = 1
+
+	maximumTaskDetails = 20
+)
+
+type ack struct {
+	Ack int `json:"Ack"`
+}
+
+// handle answers a single request. The response is always written before any status or
+// attributes the request causes to be pushed, which is the order real printers use
+func (p *Printer) handle(c *conn, message []byte) {
+	var request sdcp.Request[json.RawMessage]
+	err := json.Unmarshal(message, &request)
+	if err != nil || request.Topic != p.topic("request") {
+		return
+	}
+
+	p.mu.Lock()
+	p.requests[request.Data.Cmd]++
+	latency := p.latency
+	f, faulted := p.faults[request.Data.Cmd]
+	var data any
+	var after func()
+	if faulted {
+		data = ack{Ack: f.ack}
+	} else {
+		data, after = p.command(request.Data.Cmd, request.Data.Data)
+	}
+	p.mu.Unlock()
+
+	if faulted && f.drop {
+		return
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-p.closed:
+			return
+		}
+	}
+
+	_ = c.write(&sdcp.Response[any]{
+		TopicMessage: sdcp.TopicMessage{Topic: p.topic("response")},
+		Id:           request.Id,
+		Data: sdcp.ResponseData[any]{
+			Cmd:         request.Data.Cmd,
+			Data:        data,
+			RequestID:   request.Data.RequestID,
+			MainboardID: p.config.MainboardID,
+			TimeStamp:   int(time.Now().Unix()),
+		},
+	})
+
+	if after != nil {
+		p.mu.Lock()
+		after()
+		p.mu.Unlock()
+	}
+}
+
+// command executes a command, returning the response data and an optional function that is
+// called with p.mu held once the response has been sent. p.mu must be held
+func (p *Printer) command(command sdcp.Command, data json.RawMessage) (any, func()) {
+	switch command {
+	case sdcp.CommandStatusRefresh:
+		return ack{Ack: ackOk}, p.pushStatus
+	case sdcp.CommandAttributesRefresh:
+		return ack{Ack: ackOk}, p.pushAttributes
+	case sdcp.CommandStartPrint:
+		var request sdcp.StartPrintingRequest
+		if json.Unmarshal(data, &request) != nil {
+			return sdcp.StartPrintingResponse{Ack: sdcp.ControlAckUnknownFormat}, nil
+		}
+		return p.startPrint(request)
+	case sdcp.CommandPausePrint:
+		return sdcp.PausePrintingResponse{Ack: ackControl(p.pausePrint())}, p.pushStatus
+	case sdcp.CommandStopPrint:
+		return sdcp.StopPrintingResponse{Ack: ackControl(p.stopPrint(sdcp.TaskStatusStopped, sdcp.TaskErrorOk))}, p.pushStatus
+	case sdcp.CommandResumePrint:
+		return sdcp.ResumePrintingResponse{Ack: ackControl(p.resumePrint())}, p.pushStatus
+	case sdcp.CommandStopFeedingMaterial:
+		return sdcp.StopFeedingMaterialResponse{Ack: sdcp.ControlAckOk}, nil
+	case sdcp.CommandSkipPreheating:
+		return sdcp.SkipPreheatingResponse{Ack: sdcp.ControlAckOk}, nil
+	case sdcp.CommandChangePrinterName:
+		var request sdcp.ChangePrinterNameRequest
+		if json.Unmarshal(data, &request) != nil || request.Name == "" {
+			return sdcp.ChangePrinterNameResponse{Ack: ackError}, nil
+		}
+		p.attributes.MachineName = request.Name
+		return sdcp.ChangePrinterNameResponse{Ack: ackOk}, p.pushAttributes
+	case sdcp.CommandTerminateFileTransfer:
+		var request sdcp.TerminateFileTransferRequest
+		_ = json.Unmarshal(data, &request)
+		return sdcp.TerminateFileTransferResponse{Ack: p.terminateUpload(request.Uuid)}, p.pushStatus
+	case sdcp.CommandRetrieveFileList:
+		var request sdcp.RetrieveFileListRequest
+		if json.Unmarshal(data, &request) != nil || !request.Url.Valid() {
+			return sdcp.RetrieveFileListResponse{Ack: ackError}, nil
+		}
+		return sdcp.RetrieveFileListResponse{Ack: ackOk, FileList: p.listFiles(request.Url)}, nil
+	case sdcp.CommandBatchDeleteFiles:
+		var request sdcp.BatchDeleteFilesRequest
+		if json.Unmarshal(data, &request) != nil {
+			return sdcp.BatchDeleteFilesResponse{Ack: ackError}, nil
+		}
+		failed := p.deleteFiles(request.FileList, request.FolderList)
+		if len(failed) > 0 {
+			return sdcp.BatchDeleteFilesResponse{Ack: ackError, ErrData: failed}, nil
+		}
+		return sdcp.BatchDeleteFilesResponse{Ack: ackOk}, nil
+	case sdcp.CommandRetrieveHistoricalTasks:
+		ids := make([]string, len(p.history))
+		for i, task := range p.history {
+			ids[len(p.history)-1-i] = task.TaskId
+		}
+		return sdcp.RetrieveHistoricalTasksResponse{Ack: ackOk, HistoryData: ids}, nil
+	case sdcp.CommandRetrieveTaskDetails:
+		var request sdcp.RetrieveTaskDetailsRequest
+		if json.Unmarshal(data, &request) != nil || len(request.Id) > maximumTaskDetails {
+			return sdcp.RetrieveTaskDetailsResponse{Ack: ackError}, nil
+		}
+		details := make([]sdcp.TaskDetails, 0, len(request.Id))
+		for _, task := range p.history {
+			if slices.Contains(request.Id, task.TaskId) {
+				details = append(details, task)
+			}
+		}
+		return sdcp.RetrieveTaskDetailsResponse{Ack: ackOk, HistoryDetailList: details}, nil
+	case sdcp.CommandEnableDisableVideoStream:
+		var request sdcp.EnableDisableVideoStreamRequest
+		_ = json.Unmarshal(data, &request)
+		return p.video(request.Enable), p.pushAttributes
+	case sdcp.CommandEnableDisableTimeLapse:
+		var request sdcp.EnableDisableTimeLapseRequest
+		if json.Unmarshal(data, &request) != nil {
+			return sdcp.EnableDisableTimeLapseResponse{Ack: ackError}, nil
+		}
+		p.status.TimeLapseStatus = sdcp.TimeLapseStatusOff
+		if request.Enable == sdcp.EnableDisableEnable {
+			p.status.TimeLapseStatus = sdcp.TimeLapseStatusOn
+		}
+		return sdcp.EnableDisableTimeLapseResponse{Ack: ackOk}, p.pushStatus
+	default:
+		return ack{Ack: ackError}, nil
+	}
+}
+
+func ackControl(ok bool) sdcp.ControlAck {
+	if ok {
+		return sdcp.ControlAckOk
+	}
+	return sdcp.ControlAckBusy
+}
+
+func (p *Printer) video(enable sdcp.EnableDisable) sdcp.EnableDisableVideoStreamResponse {
+	if p.attributes.CameraStatus != sdcp.CameraStatusConnected {
+		return sdcp.EnableDisableVideoStreamResponse{Ack: sdcp.StreamAckNotExist}
+	}
+	if enable != sdcp.EnableDisableEnable {
+		p.attributes.NumberOfVideoStreamConnected = max(p.attributes.NumberOfVideoStreamConnected-1, 0)
+		return sdcp.EnableDisableVideoStreamResponse{Ack: sdcp.StreamAckSuccess}
+	}
+	if p.attributes.NumberOfVideoStreamConnected >= p.attributes.MaximumVideoStreamAllowed {
+		return sdcp.EnableDisableVideoStreamResponse{Ack: sdcp.StreamAckLimit}
+	}
+	p.attributes.NumberOfVideoStreamConnected++
+	return sdcp.EnableDisableVideoStreamResponse{
+		Ack:      sdcp.StreamAckSuccess,
+		VideoUrl: fmt.Sprintf("rtsp://%s:554/video", p.ip()),
+	}
+}
+
+// listFiles lists the files and folders directly under path, along with the usage of its storage
+func (p *Printer) listFiles(path sdcp.Path) []sdcp.FileList {
+	root := sdcp.LocalPath("")
+	storageType := sdcp.StorageTypeInternal
+	if strings.HasPrefix(string(path), string(sdcp.USBPath(""))) || path == "/usb" {
+		root = sdcp.USBPath("")
+		storageType = sdcp.StorageTypeExternal
+	}
+
+	used := 0
+	for name, f := range p.files {
+		if strings.HasPrefix(string(name), string(root)) {
+			used += f.size
+		}
+	}
+
+	list := []sdcp.FileList{{
+		Name:        path,
+		UsedSize:    used,
+		TotalSize:   storageSize,
+		StorageType: storageType,
+		Type:        sdcp.FileTypeFolder,
+	}}
+	prefix := strings.TrimSuffix(string(path), "/") + "/"
+	folders := make(map[sdcp.Path]struct{})
+	for name := range p.files {
+		rest, ok := strings.CutPrefix(string(name), prefix)
+		if !ok {
+			continue
+		}
+		if folder, _, ok := strings.Cut(rest, "/"); ok {
+			folders[sdcp.Path(prefix+folder)] = struct{}{}
+			continue
+		}
+		list = append(list, sdcp.FileList{Name: name, StorageType: storageType, Type: sdcp.FileTypeFile})
+	}
+	for folder := range folders {
+		list = append(list, sdcp.FileList{Name: folder, StorageType: storageType, Type: sdcp.FileTypeFolder})
+	}
+	sort.Slice(list[1:], func(i, j int) bool {
+		return list[i+1].Name < list[j+1].Name
+	})
+	return list
+}
+
+// deleteFiles deletes files and folders, returning the ones that do not exist
+func (p *Printer) deleteFiles(files []sdcp.Path, folders []sdcp.Path) []sdcp.Path {
+	var failed []sdcp.Path
+	for _, name := range files {
+		if _, ok := p.files[name]; !ok {
+			failed = append(failed, name)
+			continue
+		}
+		delete(p.files, name)
+	}
+	for _, folder := range folders {
+		prefix := strings.TrimSuffix(string(folder), "/") + "/"
+		found := false
+		for name := range p.files {
+			if strings.HasPrefix(string(name), prefix) {
+				delete(p.files, name)
+				found = true
+			}
+		}
+		if !found {
+			failed = append(failed, folder)
+		}
+	}
+	return failed
+}
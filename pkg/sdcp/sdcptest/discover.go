@@ -0,0 +1,43 @@
+package sdcptest
+
+import (
+	"encoding/json"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+// discover answers every discover message received until the printer is closed
+func (p *Printer) discover() {
+	defer p.wg.Done()
+	buffer := make([]byte, 1024)
+	for {
+		n, address, err := p.discovery.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		if string(buffer[:n]) != "M99999" {
+			continue
+		}
+
+		p.mu.Lock()
+		message := sdcp.DiscoverMessage{
+			ID: brandID,
+			Data: sdcp.DiscoverData{
+				MachineName:     p.attributes.MachineName,
+				MachineModel:    p.attributes.MachineModel,
+				BrandName:       p.attributes.BrandName,
+				MainboardIP:     p.attributes.MainboardIP,
+				MainboardID:     p.attributes.MainboardID,
+				ProtocolVersion: p.attributes.ProtocolVersion,
+				FirmwareVersion: p.attributes.FirmwareVersion,
+			},
+		}
+		p.mu.Unlock()
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			continue
+		}
+		_, _ = p.discovery.WriteToUDP(data, address)
+	}
+}
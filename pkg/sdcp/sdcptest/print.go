@@ -0,0 +1,178 @@
+package sdcptest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	exposureSeconds = 2.5
+)
+
+type job struct {
+	task   sdcp.TaskDetails
+	paused bool
+	cancel func()
+}
+
+// startPrint starts simulating a print of the given file, p.mu must be held
+func (p *Printer) startPrint(request sdcp.StartPrintingRequest) (any, func()) {
+	if p.busy() || p.isClosed() {
+		return sdcp.StartPrintingResponse{Ack: sdcp.ControlAckBusy}, nil
+	}
+	name := sdcp.Path(request.Filename)
+	f, ok := p.files[name]
+	if !ok {
+		name = sdcp.LocalPath(request.Filename)
+		f, ok = p.files[name]
+	}
+	if !ok {
+		return sdcp.StartPrintingResponse{Ack: sdcp.ControlAckNotFound}, nil
+	}
+
+	stop := make(chan struct{})
+	current := &job{
+		task: sdcp.TaskDetails{
+			TaskName:  string(name),
+			BeginTime: int(time.Now().Unix()),
+			TaskId:    uuid.New().String(),
+		},
+		cancel: sync.OnceFunc(func() { close(stop) }),
+	}
+	p.job = current
+	p.setMachineStatus(sdcp.MachineStatusPrinting)
+	p.status.PrintInfo = sdcp.PrintInfo{
+		Status:       sdcp.PrintInfoStatusHoming,
+		CurrentLayer: min(max(request.StartLayer, 0), f.layers),
+		TotalLayer:   f.layers,
+		TotalTicks:   int((time.Duration(f.layers) * p.config.LayerTime).Milliseconds()),
+		Filename:     string(name),
+		TaskId:       current.task.TaskId,
+	}
+
+	p.wg.Add(1)
+	go p.simulate(current, stop)
+	return sdcp.StartPrintingResponse{Ack: sdcp.ControlAckOk}, p.pushStatus
+}
+
+// simulate advances the print through each sub-status, printing one layer every LayerTime
+func (p *Printer) simulate(current *job, stop <-chan struct{}) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.LayerTime / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		if p.job != current {
+			p.mu.Unlock()
+			return
+		}
+		if current.paused {
+			p.mu.Unlock()
+			continue
+		}
+		info := &p.status.PrintInfo
+		info.CurrentTicks += int((p.config.LayerTime / 2).Milliseconds())
+		switch info.Status {
+		case sdcp.PrintInfoStatusHoming:
+			info.Status = sdcp.PrintInfoStatusDropping
+		case sdcp.PrintInfoStatusDropping, sdcp.PrintInfoStatusLifting:
+			info.Status = sdcp.PrintInfoStatusExposing
+		case sdcp.PrintInfoStatusExposing:
+			info.Status = sdcp.PrintInfoStatusLifting
+			info.CurrentLayer++
+			p.status.ReleaseFilm++
+			p.status.PrintScreen += exposureSeconds
+		}
+		if info.CurrentLayer >= info.TotalLayer {
+			info.Status = sdcp.PrintInfoStatusComplete
+			p.finishPrint(sdcp.TaskStatusCompleted, sdcp.TaskErrorOk)
+		}
+		p.pushStatus()
+		p.mu.Unlock()
+	}
+}
+
+// pausePrint pauses the current print, p.mu must be held
+func (p *Printer) pausePrint() bool {
+	if p.job == nil {
+		return false
+	}
+	p.job.paused = true
+	p.status.PrintInfo.Status = sdcp.PrintInfoStatusPaused
+	return true
+}
+
+// resumePrint resumes the current print, p.mu must be held
+func (p *Printer) resumePrint() bool {
+	if p.job == nil || !p.job.paused {
+		return false
+	}
+	p.job.paused = false
+	p.status.PrintInfo.Status = sdcp.PrintInfoStatusLifting
+	return true
+}
+
+// stopPrint stops the current print, p.mu must be held
+func (p *Printer) stopPrint(status sdcp.TaskStatus, reason sdcp.TaskError) bool {
+	if p.job == nil {
+		return false
+	}
+	p.status.PrintInfo.Status = sdcp.PrintInfoStatusStopped
+	p.finishPrint(status, reason)
+	return true
+}
+
+// finishPrint records the current print in the history and returns the machine to idle, p.mu must be held
+func (p *Printer) finishPrint(status sdcp.TaskStatus, reason sdcp.TaskError) {
+	task := p.job.task
+	task.EndTime = int(time.Now().Unix())
+	task.TaskStatus = status
+	task.ErrorStatusReason = reason
+	task.AlreadyPrintLayer = p.status.PrintInfo.CurrentLayer
+	p.history = append(p.history, task)
+	p.job.cancel()
+	p.job = nil
+	p.setMachineStatus(sdcp.MachineStatusIdle)
+}
+
+// CompletePrint finishes the current print as if its last layer had just been printed
+func (p *Printer) CompletePrint() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.job == nil {
+		return false
+	}
+	p.status.PrintInfo.CurrentLayer = p.status.PrintInfo.TotalLayer
+	p.status.PrintInfo.Status = sdcp.PrintInfoStatusComplete
+	p.finishPrint(sdcp.TaskStatusCompleted, sdcp.TaskErrorOk)
+	p.pushStatus()
+	return true
+}
+
+// FailPrint stops the current print as if the printer detected the given error
+func (p *Printer) FailPrint(reason sdcp.TaskError) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.stopPrint(sdcp.TaskStatusExceptional, reason) {
+		return false
+	}
+	p.pushStatus()
+	return true
+}
+
+// History returns every task printed by the printer, oldest first
+func (p *Printer) History() []sdcp.TaskDetails {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]sdcp.TaskDetails(nil), p.history...)
+}
@@ -0,0 +1,511 @@
+// Package sdcptest provides a simulated SDCP printer for testing Flux offline.
+package sdcptest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+var (
+	ErrListenFailed = errors.New("failed to listen")
+)
+
+const (
+	DefaultName       = "Simulated Printer"
+	DefaultModel      = "Saturn Simulator"
+	DefaultResolution = "11520x5120"
+	DefaultLayers     = 20
+	DefaultLayerTime  = 20 * time.Millisecond
+
+	brandName       = "ELEGOO"
+	brandID         = "979d4C788A4a78bC777A870F1A02867A"
+	protocolVersion = "V3.0.0"
+	firmwareVersion = "V1.0.0"
+	storageSize     = 8 * 1024 * 1024 * 1024
+)
+
+// Config configures a simulated Printer, zero values are replaced with defaults
+type Config struct {
+	MainboardID string // Defaults to a random 16 character ID
+	Name        string
+	Model       string
+	Resolution  string
+
+	// Address the SDCP websocket and file upload endpoints listen on, defaults to a random local port
+	Address string
+
+	// DiscoveryAddress is the UDP address discover messages are answered on, discovery is disabled if empty
+	DiscoveryAddress string
+
+	Layers    int           // Number of layers of every file uploaded to the printer
+	LayerTime time.Duration // Time taken to print a single layer
+}
+
+func (c *Config) defaults() {
+	if c.MainboardID == "" {
+		id := make([]byte, 8)
+		_, _ = rand.Read(id)
+		c.MainboardID = hex.EncodeToString(id)
+	}
+	if c.Name == "" {
+		c.Name = DefaultName
+	}
+	if c.Model == "" {
+		c.Model = DefaultModel
+	}
+	if c.Resolution == "" {
+		c.Resolution = DefaultResolution
+	}
+	if c.Address == "" {
+		c.Address = "127.0.0.1:0"
+	}
+	if c.Layers <= 0 {
+		c.Layers = DefaultLayers
+	}
+	if c.LayerTime <= 0 {
+		c.LayerTime = DefaultLayerTime
+	}
+}
+
+type file struct {
+	size   int
+	layers int
+}
+
+type fault struct {
+	drop bool
+	ack  int
+}
+
+type conn struct {
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+func (c *conn) write(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteJSON(v)
+}
+
+// Printer is a simulated SDCP printer. It answers discover messages, serves the SDCP websocket
+// and file upload endpoints, responds to every Command, and simulates prints layer by layer.
+// Faults can be injected to test how Flux handles misbehaving printers.
+type Printer struct {
+	config    Config
+	listener  net.Listener
+	server    *http.Server
+	discovery *net.UDPConn
+	upgrader  websocket.Upgrader
+
+	mu         sync.Mutex
+	status     sdcp.Status
+	attributes sdcp.Attributes
+	files      map[sdcp.Path]*file
+	history    []sdcp.TaskDetails
+	upload     *upload
+	job        *job
+	latency    time.Duration
+	faults     map[sdcp.Command]fault
+	requests   map[sdcp.Command]int
+	stalled    chan struct{} // Closed once the printer stops stalling, nil if it is not stalled
+	conns      map[*conn]struct{}
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPrinter starts a simulated Printer
+func NewPrinter(config Config) (*Printer, error) {
+	config.defaults()
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return nil, errors.Join(ErrListenFailed, err)
+	}
+
+	p := &Printer{
+		config:   config,
+		listener: listener,
+		files:    make(map[sdcp.Path]*file),
+		faults:   make(map[sdcp.Command]fault),
+		requests: make(map[sdcp.Command]int),
+		conns:    make(map[*conn]struct{}),
+		closed:   make(chan struct{}),
+	}
+	p.status = sdcp.Status{
+		CurrentStatus:   []sdcp.MachineStatus{sdcp.MachineStatusIdle},
+		PreviousStatus:  sdcp.MachineStatusIdle,
+		TempOfUVLED:     25,
+		TimeLapseStatus: sdcp.TimeLapseStatusOff,
+		TempOfBox:       25,
+		TempTargetBox:   25,
+	}
+	p.attributes = sdcp.Attributes{
+		MachineName:               config.Name,
+		MachineModel:              config.Model,
+		BrandName:                 brandName,
+		ProtocolVersion:           protocolVersion,
+		FirmwareVersion:           firmwareVersion,
+		Resolution:                config.Resolution,
+		XYZsize:                   "218.88x122.88x260",
+		MainboardIP:               p.ip(),
+		MainboardID:               config.MainboardID,
+		MaximumVideoStreamAllowed: 1,
+		NetworkStatus:             sdcp.NetworkStatusWlan,
+		UsbDiskStatus:             sdcp.UsbDiskStatusDisconnected,
+		Capabilities:              []sdcp.Capabilities{sdcp.CapabilitiesFileTransfer, sdcp.CapabilitiesPrintControl, sdcp.CapabilitiesVideoStream},
+		SupportFileType:           []sdcp.SupportedFileType{sdcp.SupportedFileTypeCTB, sdcp.SupportedFileTypeGOO},
+		DevicesStatus: sdcp.DeviceStatus{
+			TempSensorStatusOfUVLED: sdcp.TempSensorStatusOfUVLEDNormal,
+			LCDStatus:               sdcp.LCDStatusConnected,
+			SgStatus:                sdcp.SgStatusNormal,
+			ZMotorStatus:            sdcp.ZMotorStatusConnected,
+			RotateMotorStatus:       sdcp.RotateMotorStatusConnected,
+			ReleaseFilmState:        sdcp.ReleaseFilmStateNormal,
+			XMotorStatus:            sdcp.XMotorStatusConnected,
+		},
+		ReleaseFilmMax:  60000,
+		TempOfUVLEDMax:  70,
+		CameraStatus:    sdcp.CameraStatusConnected,
+		RemainingMemory: storageSize * 8,
+	}
+
+	if config.DiscoveryAddress != "" {
+		address, err := net.ResolveUDPAddr("udp", config.DiscoveryAddress)
+		if err == nil {
+			p.discovery, err = net.ListenUDP("udp", address)
+		}
+		if err != nil {
+			_ = listener.Close()
+			return nil, errors.Join(ErrListenFailed, err)
+		}
+		p.wg.Add(1)
+		go p.discover()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/websocket", p.serveWebsocket)
+	mux.HandleFunc("/uploadFile/upload", p.serveUpload)
+	p.server = &http.Server{Handler: mux}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		_ = p.server.Serve(listener)
+	}()
+
+	return p, nil
+}
+
+// ID returns the mainboard ID of the printer
+func (p *Printer) ID() string {
+	return p.config.MainboardID
+}
+
+// Addr returns the address the SDCP websocket and file upload endpoints listen on
+func (p *Printer) Addr() *net.TCPAddr {
+	return p.listener.Addr().(*net.TCPAddr)
+}
+
+// DiscoveryAddr returns the address discover messages are answered on, or nil if discovery is disabled
+func (p *Printer) DiscoveryAddr() *net.UDPAddr {
+	if p.discovery == nil {
+		return nil
+	}
+	return p.discovery.LocalAddr().(*net.UDPAddr)
+}
+
+// Registration returns the sdcp.Registration used to connect to the printer
+func (p *Printer) Registration() sdcp.Registration {
+	return sdcp.Registration{
+		ID:   p.config.MainboardID,
+		IP:   p.ip(),
+		Port: p.Addr().Port,
+	}
+}
+
+func (p *Printer) ip() string {
+	return p.Addr().IP.String()
+}
+
+// Status returns the current status of the printer
+func (p *Printer) Status() sdcp.Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.snapshotStatus()
+}
+
+// Attributes returns the current attributes of the printer
+func (p *Printer) Attributes() sdcp.Attributes {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.snapshotAttributes()
+}
+
+// Update changes the status and attributes of the printer and pushes both to every connection
+func (p *Printer) Update(update func(status *sdcp.Status, attributes *sdcp.Attributes)) {
+	p.mu.Lock()
+	update(&p.status, &p.attributes)
+	p.pushStatus()
+	p.pushAttributes()
+	p.mu.Unlock()
+}
+
+// AddFile adds a file that can be printed to the printer's storage
+func (p *Printer) AddFile(path sdcp.Path, size int, layers int) {
+	p.mu.Lock()
+	p.files[path] = &file{size: size, layers: layers}
+	p.mu.Unlock()
+}
+
+// HasFile returns true if the file exists in the printer's storage
+func (p *Printer) HasFile(path sdcp.Path) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.files[path]
+	return ok
+}
+
+// SetLatency delays every response sent by the printer
+func (p *Printer) SetLatency(latency time.Duration) {
+	p.mu.Lock()
+	p.latency = latency
+	p.mu.Unlock()
+}
+
+// FailCommand makes the printer answer every request for command with the given Ack
+func (p *Printer) FailCommand(command sdcp.Command, ack int) {
+	p.mu.Lock()
+	p.faults[command] = fault{ack: ack}
+	p.mu.Unlock()
+}
+
+// DropCommand makes the printer never answer requests for command
+func (p *Printer) DropCommand(command sdcp.Command) {
+	p.mu.Lock()
+	p.faults[command] = fault{drop: true}
+	p.mu.Unlock()
+}
+
+// Stall makes the printer stop reading from its websocket connections without closing them, so
+// neither requests nor pings are answered
+func (p *Printer) Stall() {
+	p.mu.Lock()
+	if p.stalled == nil {
+		p.stalled = make(chan struct{})
+	}
+	p.mu.Unlock()
+}
+
+// ClearFaults removes the latency and every fault injected into the printer
+func (p *Printer) ClearFaults() {
+	p.mu.Lock()
+	p.latency = 0
+	clear(p.faults)
+	if p.stalled != nil {
+		close(p.stalled)
+		p.stalled = nil
+	}
+	p.mu.Unlock()
+}
+
+// Disconnect closes every websocket connection to the printer
+func (p *Printer) Disconnect() {
+	p.mu.Lock()
+	for c := range p.conns {
+		_ = c.ws.Close()
+	}
+	p.mu.Unlock()
+}
+
+// Connections returns the number of open websocket connections to the printer
+func (p *Printer) Connections() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// Requests returns the number of requests for command the printer has received, including requests it dropped
+func (p *Printer) Requests(command sdcp.Command) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests[command]
+}
+
+// PushError sends an error message to every connection
+func (p *Printer) PushError(code sdcp.ErrorCode) {
+	p.mu.Lock()
+	p.pushError(code)
+	p.mu.Unlock()
+}
+
+// PushNotice sends a notice message to every connection
+func (p *Printer) PushNotice(t sdcp.NotificationType, message string) {
+	p.mu.Lock()
+	p.broadcast(&sdcp.Notification{
+		TopicMessage: sdcp.TopicMessage{Topic: p.topic("notice")},
+		Id:           brandID,
+		Data: sdcp.NotificationData{
+			Data:        sdcp.NotificationTypeData{Message: message, Type: t},
+			MainboardID: p.config.MainboardID,
+			TimeStamp:   int(time.Now().Unix()),
+		},
+	})
+	p.mu.Unlock()
+}
+
+// Close disconnects every connection and stops the printer
+func (p *Printer) Close() {
+	p.mu.Lock()
+	if p.isClosed() {
+		p.mu.Unlock()
+		return
+	}
+	close(p.closed)
+	p.mu.Unlock()
+	_ = p.server.Close()
+	if p.discovery != nil {
+		_ = p.discovery.Close()
+	}
+	p.Disconnect()
+	p.mu.Lock()
+	if p.job != nil {
+		p.job.cancel()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (p *Printer) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws}
+	p.mu.Lock()
+	if p.isClosed() {
+		p.mu.Unlock()
+		_ = ws.Close()
+		return
+	}
+	p.conns[c] = struct{}{}
+	p.wg.Add(1)
+	p.mu.Unlock()
+	defer p.wg.Done()
+	defer func() {
+		p.mu.Lock()
+		delete(p.conns, c)
+		p.mu.Unlock()
+		_ = ws.Close()
+	}()
+
+	for {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		stalled := p.stalled
+		p.mu.Unlock()
+		if stalled != nil {
+			select {
+			case <-stalled:
+			case <-p.closed:
+				return
+			}
+		}
+		p.handle(c, message)
+	}
+}
+
+func (p *Printer) topic(kind string) string {
+	return fmt.Sprintf("sdcp/%s/%s", kind, p.config.MainboardID)
+}
+
+// broadcast sends v to every connection, p.mu must be held
+func (p *Printer) broadcast(v any) {
+	for c := range p.conns {
+		_ = c.write(v)
+	}
+}
+
+// pushStatus sends the current status to every connection, p.mu must be held
+func (p *Printer) pushStatus() {
+	p.broadcast(&sdcp.StatusMessage{
+		TopicMessage: sdcp.TopicMessage{Topic: p.topic("status")},
+		Status:       p.snapshotStatus(),
+		MainboardID:  p.config.MainboardID,
+		TimeStamp:    int(time.Now().Unix()),
+	})
+}
+
+// pushError sends an error message to every connection, p.mu must be held
+func (p *Printer) pushError(code sdcp.ErrorCode) {
+	p.broadcast(&sdcp.Error{
+		TopicMessage: sdcp.TopicMessage{Topic: p.topic("error")},
+		Id:           brandID,
+		Data: sdcp.ErrorData{
+			Data:        sdcp.ErrorCodeData{ErrorCode: code},
+			MainboardID: p.config.MainboardID,
+			TimeStamp:   int(time.Now().Unix()),
+		},
+	})
+}
+
+// pushAttributes sends the current attributes to every connection, p.mu must be held
+func (p *Printer) pushAttributes() {
+	p.broadcast(&sdcp.AttributesMessage{
+		TopicMessage: sdcp.TopicMessage{Topic: p.topic("attributes")},
+		Attributes:   p.snapshotAttributes(),
+		MainboardID:  p.config.MainboardID,
+		TimeStamp:    int(time.Now().Unix()),
+	})
+}
+
+func (p *Printer) snapshotStatus() sdcp.Status {
+	s := p.status
+	s.CurrentStatus = append([]sdcp.MachineStatus(nil), p.status.CurrentStatus...)
+	return s
+}
+
+func (p *Printer) snapshotAttributes() sdcp.Attributes {
+	a := p.attributes
+	a.Capabilities = append([]sdcp.Capabilities(nil), p.attributes.Capabilities...)
+	a.SupportFileType = append([]sdcp.SupportedFileType(nil), p.attributes.SupportFileType...)
+	return a
+}
+
+// setMachineStatus replaces the current status of the machine, p.mu must be held
+func (p *Printer) setMachineStatus(status sdcp.MachineStatus) {
+	if len(p.status.CurrentStatus) > 0 {
+		p.status.PreviousStatus = p.status.CurrentStatus[0]
+	}
+	p.status.CurrentStatus = []sdcp.MachineStatus{status}
+}
+
+func (p *Printer) isClosed() bool {
+	select {
+	case <-p.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Printer) busy() bool {
+	for _, s := range p.status.CurrentStatus {
+		if s != sdcp.MachineStatusIdle {
+			return true
+		}
+	}
+	return false
+}
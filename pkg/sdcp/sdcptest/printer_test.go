@@ -0,0 +1,288 @@
+package sdcptest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+func newMachine(t *testing.T, config Config, options ...sdcp.ConnectionOptions) (*Printer, *sdcp.SDCP, *sdcp.Machine) {
+	t.Helper()
+	p, err := NewPrinter(config)
+	require.NoError(t, err)
+	t.Cleanup(p.Close)
+
+	s := sdcp.New(logging.Test(t, logging.Slog, t.Name()), nil)
+	t.Cleanup(s.Close)
+	for _, o := range options {
+		s.SetConnectionOptions(o)
+	}
+	require.NoError(t, s.Register(p.Registration()))
+	m, ok := s.GetMachine(p.ID())
+	require.True(t, ok)
+	return p, s, m
+}
+
+func waitFor(t *testing.T, subscription *sdcp.Subscription, match func(e sdcp.Event) bool) sdcp.Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-subscription.C:
+			if match(e) {
+				return e
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	p, _, m := newMachine(t, Config{Name: "Test Printer", DiscoveryAddress: "127.0.0.1:0"})
+	ctx := context.Background()
+
+	require.Equal(t, sdcp.ConnectionStateOnline, m.State())
+	require.Equal(t, "Test Printer", m.Attributes().MachineName)
+	require.Equal(t, []sdcp.MachineStatus{sdcp.MachineStatusIdle}, m.Status().CurrentStatus)
+
+	discoverCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	discovered, err := sdcp.DiscoverAddress(logging.Test(t, logging.Slog, t.Name()), discoverCtx, p.DiscoveryAddr())
+	require.NoError(t, err)
+	require.Len(t, discovered, 1)
+	require.Equal(t, p.ID(), discovered[0].Data.MainboardID)
+
+	data := bytes.Repeat([]byte("flux"), 512*1024)
+	_, err = m.UploadFile(ctx, bytes.NewReader(data), "model.ctb", int64(len(data)))
+	require.NoError(t, err)
+	require.True(t, p.HasFile(sdcp.LocalPath("model.ctb")))
+
+	files, err := m.WalkFiles(ctx, "/local")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, []sdcp.Storage{{StorageType: sdcp.StorageTypeInternal, UsedSize: len(data), TotalSize: storageSize}}, sdcp.StorageUsage(files))
+
+	subscription := m.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	defer subscription.Close()
+	_, err = m.StartPrint(ctx, "model.ctb", 0)
+	require.NoError(t, err)
+	_, err = m.StartPrint(ctx, "model.ctb", 0)
+	require.ErrorIs(t, err, sdcp.ErrControlBusy)
+	waitFor(t, subscription, func(e sdcp.Event) bool {
+		return e.Type == sdcp.EventTypeStatus && e.Status.PrintInfo.Status == sdcp.PrintInfoStatusComplete
+	})
+	require.Equal(t, DefaultLayers, m.Status().ReleaseFilm)
+
+	history, err := m.History(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	tasks, err := m.TaskDetails(ctx, history...)
+	require.NoError(t, err)
+	require.Equal(t, sdcp.TaskStatusCompleted, tasks[0].TaskStatus)
+	require.Equal(t, DefaultLayers, tasks[0].AlreadyPrintLayer)
+
+	_, err = m.StartPrint(ctx, "model.ctb", 0)
+	require.NoError(t, err)
+	require.True(t, p.FailPrint(sdcp.TaskErrorResinLack))
+	require.Equal(t, sdcp.TaskErrorResinLack, p.History()[1].ErrorStatusReason)
+
+	_, err = m.DeleteFiles(ctx, []sdcp.Path{sdcp.LocalPath("model.ctb"), sdcp.LocalPath("missing.ctb")}, nil)
+	var deleteErr *sdcp.DeleteFilesError
+	require.ErrorAs(t, err, &deleteErr)
+	require.Equal(t, []sdcp.Path{sdcp.LocalPath("missing.ctb")}, deleteErr.Failed)
+	require.False(t, p.HasFile(sdcp.LocalPath("model.ctb")))
+
+	_, err = m.StartPrint(ctx, "model.ctb", 0)
+	require.ErrorIs(t, err, sdcp.ErrControlFileNotFound)
+}
+
+func TestPrinterFaults(t *testing.T) {
+	p, _, m := newMachine(t, Config{})
+	ctx := context.Background()
+
+	p.FailCommand(sdcp.CommandPausePrint, int(sdcp.ControlAckBusy))
+	_, err := m.PausePrint(ctx)
+	require.ErrorIs(t, err, sdcp.ErrControlBusy)
+
+	p.ClearFaults()
+	p.SetLatency(time.Second)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = m.StatusRefresh(timeoutCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	p.ClearFaults()
+	subscription := m.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	defer subscription.Close()
+	p.PushError(sdcp.ErrorCodeFormatFailed)
+	e := waitFor(t, subscription, func(e sdcp.Event) bool {
+		return e.Type == sdcp.EventTypeError
+	})
+	require.Equal(t, sdcp.ErrorCodeFormatFailed, e.Error.Data.ErrorCode)
+
+	p.Disconnect()
+	waitFor(t, subscription, func(e sdcp.Event) bool {
+		return e.Type == sdcp.EventTypeState && e.State == sdcp.ConnectionStateOffline
+	})
+	waitFor(t, subscription, func(e sdcp.Event) bool {
+		return e.Type == sdcp.EventTypeState && e.State == sdcp.ConnectionStateOnline
+	})
+	require.Eventually(t, func() bool {
+		return p.Connections() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRequestPolicy(t *testing.T) {
+	p, s, m := newMachine(t, Config{})
+	ctx := context.Background()
+
+	s.SetRequestPolicy(sdcp.CommandRetrieveFileList, sdcp.RequestPolicy{Timeout: 50 * time.Millisecond, Attempts: 3, Backoff: 10 * time.Millisecond})
+	p.DropCommand(sdcp.CommandRetrieveFileList)
+	_, err := m.ListFiles(ctx, sdcp.Path("/local"))
+	require.ErrorIs(t, err, sdcp.ErrTimeout)
+	var timeoutErr *sdcp.TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, 3, timeoutErr.Attempts)
+
+	// Requests waiting for a response fail as soon as the connection is lost
+	p.DropCommand(sdcp.CommandStatusRefresh)
+	requests := p.Requests(sdcp.CommandStatusRefresh)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := m.StatusRefresh(ctx)
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool {
+		return p.Requests(sdcp.CommandStatusRefresh) > requests
+	}, 5*time.Second, time.Millisecond)
+	p.Disconnect()
+	select {
+	case err = <-errCh:
+		require.ErrorIs(t, err, sdcp.ErrDisconnected)
+	case <-time.After(time.Second):
+		t.Fatal("request was not failed when the connection was lost")
+	}
+}
+
+func TestStaleConnection(t *testing.T) {
+	p, _, m := newMachine(t, Config{}, sdcp.ConnectionOptions{PingInterval: 50 * time.Millisecond, StaleTimeout: 300 * time.Millisecond})
+	subscription := m.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	defer subscription.Close()
+
+	// A printer that stops answering, including pings, is disconnected and redialed
+	p.Stall()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := m.StatusRefresh(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	waitFor(t, subscription, func(e sdcp.Event) bool {
+		return e.Type == sdcp.EventTypeState && e.State == sdcp.ConnectionStateOffline
+	})
+
+	p.ClearFaults()
+	waitFor(t, subscription, func(e sdcp.Event) bool {
+		return e.Type == sdcp.EventTypeState && e.State == sdcp.ConnectionStateOnline
+	})
+	_, err = m.StatusRefresh(context.Background())
+	require.NoError(t, err)
+}
+
+func TestRefreshWait(t *testing.T) {
+	p, s, m := newMachine(t, Config{})
+	ctx := context.Background()
+
+	// A printer that acknowledges a refresh but never pushes its status does not block waiters or readers
+	s.SetRequestPolicy(sdcp.CommandStatusRefresh, sdcp.RequestPolicy{Timeout: 100 * time.Millisecond, Attempts: 1})
+	p.FailCommand(sdcp.CommandStatusRefresh, 0)
+	_, version := m.StatusSnapshot()
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := m.StatusRefreshWait(ctx)
+		errCh <- err
+	}()
+	require.Equal(t, sdcp.MachineStatusIdle, m.Status().CurrentStatus[0])
+	require.ErrorIs(t, <-errCh, sdcp.ErrTimeout)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, _, err := m.WaitStatus(timeoutCtx, version)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	p.ClearFaults()
+	status, err := m.StatusRefreshWait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, sdcp.MachineStatusIdle, status.CurrentStatus[0])
+	_, next, err := m.WaitStatus(ctx, version)
+	require.NoError(t, err)
+	require.Greater(t, next, version)
+
+	// Waiters fail once the connection is lost, whether they started waiting before or after it was
+	// lost, and closing the printer makes sure it is not reconnected to in the meantime
+	_, version = m.AttributesSnapshot()
+	go func() {
+		_, _, err := m.WaitAttributes(ctx, version)
+		errCh <- err
+	}()
+	p.Close()
+	select {
+	case err = <-errCh:
+		require.ErrorIs(t, err, sdcp.ErrDisconnected)
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not woken when the connection was lost")
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	p, _, m := newMachine(t, Config{})
+	ctx := context.Background()
+
+	// Every error wraps the error of its command, and rejected responses are still returned
+	p.FailCommand(sdcp.CommandRetrieveFileList, 3)
+	f, err := m.ListFiles(ctx, sdcp.Path("/local"))
+	require.ErrorIs(t, err, sdcp.ErrRetrieveFileListFailed)
+	require.ErrorIs(t, err, sdcp.ErrRejected)
+	require.Equal(t, 3, f.Ack)
+
+	video, err := m.EnableDisableVideo(ctx, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, video.VideoUrl)
+	_, err = m.EnableDisableVideo(ctx, true)
+	require.ErrorIs(t, err, sdcp.ErrEnableDisableVideoFailed)
+	require.ErrorIs(t, err, sdcp.ErrStreamLimit)
+	require.NotErrorIs(t, err, sdcp.ErrStatusRefreshFailed)
+}
+
+func TestApplySettings(t *testing.T) {
+	p, s, m := newMachine(t, Config{})
+	ctx := context.Background()
+
+	name, timeLapse := "Renamed Printer", true
+	results := m.ApplySettings(ctx, sdcp.Settings{Name: &name, TimeLapse: &timeLapse})
+	require.Equal(t, []sdcp.SettingResult{{Setting: sdcp.SettingName, Applied: true}, {Setting: sdcp.SettingTimeLapse, Applied: true}}, results)
+	require.Equal(t, name, m.Attributes().MachineName)
+	require.Equal(t, sdcp.TimeLapseStatusOn, m.Status().TimeLapseStatus)
+
+	// Settings the machine already has are applied without being sent
+	p.DropCommand(sdcp.CommandChangePrinterName)
+	p.DropCommand(sdcp.CommandEnableDisableTimeLapse)
+	results = m.ApplySettings(ctx, sdcp.Settings{Name: &name, TimeLapse: &timeLapse})
+	require.Equal(t, []sdcp.SettingResult{{Setting: sdcp.SettingName, Applied: true}, {Setting: sdcp.SettingTimeLapse, Applied: true}}, results)
+	p.ClearFaults()
+
+	// Settings the machine acknowledges but never pushes are not applied
+	s.SetRequestPolicy(sdcp.CommandEnableDisableTimeLapse, sdcp.RequestPolicy{Timeout: 100 * time.Millisecond, Attempts: 1})
+	p.FailCommand(sdcp.CommandEnableDisableTimeLapse, 0)
+	timeLapse = false
+	results = m.ApplySettings(ctx, sdcp.Settings{TimeLapse: &timeLapse})
+	require.Len(t, results, 1)
+	require.False(t, results[0].Applied)
+	require.ErrorIs(t, results[0].Err, sdcp.ErrSettingNotConfirmed)
+	require.ErrorIs(t, results[0].Err, sdcp.ErrTimeout)
+}
@@ -0,0 +1,134 @@
+package sdcptest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	uploadSuccessCode = "000000"
+	uploadErrorCode   = "100001"
+	maximumUploadSize = 32 * 1024 * 1024
+)
+
+type upload struct {
+	uuid     string
+	filename string
+	md5      string
+	size     int64
+	data     bytes.Buffer
+}
+
+// serveUpload receives a chunk of a file, storing the file once every chunk has been received
+func (p *Printer) serveUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	err := r.ParseMultipartForm(maximumUploadSize)
+	if err != nil {
+		writeUploadResponse(w, "File", err.Error())
+		return
+	}
+	offset, err := strconv.ParseInt(r.FormValue("Offset"), 10, 64)
+	if err != nil {
+		writeUploadResponse(w, "Offset", "invalid offset")
+		return
+	}
+	size, err := strconv.ParseInt(r.FormValue("TotalSize"), 10, 64)
+	if err != nil || size <= 0 {
+		writeUploadResponse(w, "TotalSize", "invalid total size")
+		return
+	}
+	part, header, err := r.FormFile("File")
+	if err != nil {
+		writeUploadResponse(w, "File", "missing file")
+		return
+	}
+	defer func() {
+		_ = part.Close()
+	}()
+	chunk, err := io.ReadAll(part)
+	if err != nil {
+		writeUploadResponse(w, "File", err.Error())
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := r.FormValue("Uuid")
+	if offset == 0 && (p.upload == nil || p.upload.uuid != id) {
+		if p.busy() {
+			writeUploadResponse(w, "Uuid", "printer is busy")
+			return
+		}
+		p.upload = &upload{
+			uuid:     id,
+			filename: header.Filename,
+			md5:      r.FormValue("S-File-MD5"),
+			size:     size,
+		}
+		p.setMachineStatus(sdcp.MachineStatusFileTransferring)
+		p.pushStatus()
+	}
+	u := p.upload
+	if u == nil || u.uuid != id {
+		writeUploadResponse(w, "Uuid", "unknown transfer")
+		return
+	}
+	if offset != int64(u.data.Len()) || offset+int64(len(chunk)) > u.size {
+		writeUploadResponse(w, "Offset", "unexpected offset")
+		return
+	}
+	u.data.Write(chunk)
+
+	if int64(u.data.Len()) == u.size {
+		p.upload = nil
+		p.setMachineStatus(sdcp.MachineStatusIdle)
+		p.pushStatus()
+		sum := md5.Sum(u.data.Bytes())
+		if hex.EncodeToString(sum[:]) != u.md5 {
+			p.pushError(sdcp.ErrorCodeMD5Failed)
+			writeUploadResponse(w, "S-File-MD5", "md5 check failed")
+			return
+		}
+		p.files[sdcp.LocalPath(u.filename)] = &file{size: int(u.size), layers: p.config.Layers}
+	}
+
+	writeUploadResponse(w, "", "")
+}
+
+// terminateUpload cancels the upload with the given UUID, p.mu must be held
+func (p *Printer) terminateUpload(id string) sdcp.FileTransferAck {
+	if p.upload == nil {
+		return sdcp.FileTransferAckNotTransfer
+	}
+	if p.upload.uuid != id {
+		return sdcp.FileTransferAckNotFound
+	}
+	p.upload = nil
+	p.setMachineStatus(sdcp.MachineStatusIdle)
+	return sdcp.FileTransferAckSuccess
+}
+
+func writeUploadResponse(w http.ResponseWriter, field string, message string) {
+	res := sdcp.UploadFileResponse{
+		Code:    uploadSuccessCode,
+		Success: true,
+	}
+	if message != "" {
+		res = sdcp.UploadFileResponse{
+			Code:     uploadErrorCode,
+			Messages: []sdcp.UploadFileMessage{{Field: field, Message: message}},
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,106 @@
+package sdcp
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrInvalidName         = errors.New("invalid machine name")
+	ErrSettingNotConfirmed = errors.New("machine did not confirm setting")
+)
+
+const (
+	SettingName      = "name"
+	SettingTimeLapse = "time_lapse"
+)
+
+// Settings are changes to the settings of a machine, settings that are nil are left unchanged
+type Settings struct {
+	Name      *string
+	TimeLapse *bool
+}
+
+// SettingResult reports whether a single setting took effect, which it has only once the
+// machine has pushed attributes or a status with the new value
+type SettingResult struct {
+	Setting string
+	Applied bool
+	Err     error
+}
+
+// SetName renames the machine
+func (m *Machine) SetName(ctx context.Context, name string) (*ChangePrinterNameResponse, error) {
+	if name == "" {
+		return nil, errors.Join(ErrChangePrinterNameFailed, ErrInvalidName)
+	}
+	return changePrinterNameCommand.send(m, ctx, ChangePrinterNameRequest{Name: name})
+}
+
+// ApplySettings applies each setting in turn, waiting for the machine to confirm each one before
+// applying the next, and returns a result for every setting that was requested. Settings that the
+// machine has already pushed are applied without being sent, as the machine may never push them again.
+func (m *Machine) ApplySettings(ctx context.Context, settings Settings) []SettingResult {
+	var results []SettingResult
+	if settings.Name != nil {
+		name := *settings.Name
+		results = append(results, m.applySetting(SettingName, func() error {
+			attributes, version := m.attributes.load()
+			if version > 0 && attributes.MachineName == name {
+				return nil
+			}
+			_, err := m.SetName(ctx, name)
+			if err != nil {
+				return err
+			}
+			return confirm(m, ctx, CommandChangePrinterName, m.attributes, version, func(a *Attributes) bool {
+				return a.MachineName == name
+			})
+		}))
+	}
+	if settings.TimeLapse != nil {
+		status := TimeLapseStatusOff
+		if *settings.TimeLapse {
+			status = TimeLapseStatusOn
+		}
+		results = append(results, m.applySetting(SettingTimeLapse, func() error {
+			current, version := m.status.load()
+			if version > 0 && current.TimeLapseStatus == status {
+				return nil
+			}
+			_, err := m.SetTimeLapse(ctx, *settings.TimeLapse)
+			if err != nil {
+				return err
+			}
+			return confirm(m, ctx, CommandEnableDisableTimeLapse, m.status, version, func(s *Status) bool {
+				return s.TimeLapseStatus == status
+			})
+		}))
+	}
+	return results
+}
+
+func (m *Machine) applySetting(setting string, apply func() error) SettingResult {
+	err := apply()
+	if err != nil {
+		m.logger.Warn().Err(err).Str("setting", setting).Msg("failed to apply setting")
+	}
+	return SettingResult{Setting: setting, Applied: err == nil, Err: err}
+}
+
+// confirm waits for the machine to push a value newer than version that matches, bounded by the
+// timeout of requests for command
+func confirm[T any](m *Machine, ctx context.Context, command Command, s *store[T], version uint64, match func(*T) bool) error {
+	ctx, cancel := m.pushContext(ctx, command)
+	defer cancel()
+	for {
+		value, next, err := s.wait(ctx, version, m.checkConnected)
+		if err != nil {
+			return errors.Join(ErrSettingNotConfirmed, err)
+		}
+		if match(&value) {
+			return nil
+		}
+		version = next
+	}
+}
@@ -0,0 +1,73 @@
+package sdcp
+
+import (
+	"context"
+	"sync"
+)
+
+// store holds the latest value pushed by a machine together with a version that is incremented
+// on every push. Readers never wait for refreshes, and callers can wait for a value newer than
+// a version they have already seen.
+type store[T any] struct {
+	mu      sync.RWMutex
+	value   T
+	version uint64
+	changed chan struct{} // Closed and replaced whenever the value is set or waiters are woken
+}
+
+func newStore[T any]() *store[T] {
+	return &store[T]{
+		changed: make(chan struct{}),
+	}
+}
+
+// load returns the current value and its version
+func (s *store[T]) load() (T, uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value, s.version
+}
+
+// set replaces the value, returning the previous value and the new version
+func (s *store[T]) set(value T) (T, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.value
+	s.value = value
+	s.version++
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return previous, s.version
+}
+
+// wake makes every waiter check whether it should stop waiting, without changing the value
+func (s *store[T]) wake() {
+	s.mu.Lock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// wait returns the first value with a version newer than version. Until such a value is set,
+// check is called every time waiters are woken, and waiting stops if it returns an error.
+func (s *store[T]) wait(ctx context.Context, version uint64, check func() error) (T, uint64, error) {
+	for {
+		s.mu.RLock()
+		value, current, changed := s.value, s.version, s.changed
+		s.mu.RUnlock()
+		if current > version {
+			return value, current, nil
+		}
+		if err := check(); err != nil {
+			var zero T
+			return zero, current, err
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, current, context.Cause(ctx)
+		case <-changed:
+		}
+	}
+}
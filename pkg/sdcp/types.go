@@ -147,31 +147,31 @@ type StartPrintingResponse struct {
 type PausePrintingRequest struct{}
 
 type PausePrintingResponse struct {
-	Ack int `json:"Ack"` // Acknowledgement
+	Ack ControlAck `json:"Ack"` // Acknowledgement
 }
 
 type StopPrintingRequest struct{}
 
 type StopPrintingResponse struct {
-	Ack int `json:"Ack"` // Acknowledgement
+	Ack ControlAck `json:"Ack"` // Acknowledgement
 }
 
 type ResumePrintingRequest struct{}
 
 type ResumePrintingResponse struct {
-	Ack int `json:"Ack"` // Acknowledgement
+	Ack ControlAck `json:"Ack"` // Acknowledgement
 }
 
 type StopFeedingMaterialRequest struct{}
 
 type StopFeedingMaterialResponse struct {
-	Ack int `json:"Ack"` // Acknowledgement
+	Ack ControlAck `json:"Ack"` // Acknowledgement
 }
 
 type SkipPreheatingRequest struct{}
 
 type SkipPreheatingResponse struct {
-	Ack int `json:"Ack"` // Acknowledgement
+	Ack ControlAck `json:"Ack"` // Acknowledgement
 }
 
 type ChangePrinterNameRequest struct {
@@ -250,3 +250,15 @@ type ResponseData[T any] struct {
 	MainboardID string  `json:"MainboardID"` // Motherboard ID
 	TimeStamp   int     `json:"TimeStamp"`   // Timestamp
 }
+
+type UploadFileMessage struct {
+	Field   string `json:"field"`   // Field that caused the error
+	Message string `json:"message"` // Error Message
+}
+
+type UploadFileResponse struct {
+	Code     string              `json:"code"`     // Response Code, "000000" on success
+	Messages []UploadFileMessage `json:"messages"` // Error Messages
+	Data     any                 `json:"data"`     // Response Data
+	Success  bool                `json:"success"`  // Whether the chunk was accepted
+}
@@ -0,0 +1,308 @@
+package sdcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadFailed                = errors.New("file upload failed")
+	ErrUploadInProgress            = errors.New("file upload already in progress")
+	ErrUploadNotInProgress         = errors.New("no file upload in progress")
+	ErrUploadSizeMismatch          = errors.New("file size does not match")
+	ErrUploadRejected              = errors.New("machine rejected file chunk")
+	ErrTerminateFileTransferFailed = errors.New("terminate file transfer failed")
+)
+
+var (
+	ErrFileTransferNotTransferring = errors.New("machine is not transferring files")
+	ErrFileTransferChecking        = errors.New("machine is already verifying the file")
+	ErrFileTransferNotFound        = errors.New("file not found")
+	ErrFileTransferUnknownAck      = errors.New("unknown file transfer acknowledgement")
+)
+
+const (
+	uploadPath        = "/uploadFile/upload"
+	uploadChunkSize   = 1024 * 1024
+	uploadSuccessCode = "000000"
+	uploadTimeout     = 30 * time.Second
+)
+
+// Err returns the error associated with the FileTransferAck, or nil if the FileTransferAck is FileTransferAckSuccess
+func (a FileTransferAck) Err() error {
+	switch a {
+	case FileTransferAckSuccess:
+		return nil
+	case FileTransferAckNotTransfer:
+		return ErrFileTransferNotTransferring
+	case FileTransferAckChecking:
+		return ErrFileTransferChecking
+	case FileTransferAckNotFound:
+		return ErrFileTransferNotFound
+	default:
+		return fmt.Errorf("%w: %d", ErrFileTransferUnknownAck, a)
+	}
+}
+
+// Transfer is a snapshot of the progress of a file upload to a machine
+type Transfer struct {
+	UUID      string
+	Filename  string
+	MD5       string
+	Size      int64
+	Sent      int64
+	StartedAt time.Time
+}
+
+type transfer struct {
+	uuid      string
+	filename  string
+	md5       string
+	size      int64
+	sent      atomic.Int64
+	startedAt time.Time
+	cancel    context.CancelFunc
+}
+
+func (t *transfer) snapshot() *Transfer {
+	return &Transfer{
+		UUID:      t.uuid,
+		Filename:  t.filename,
+		MD5:       t.md5,
+		Size:      t.size,
+		Sent:      t.sent.Load(),
+		StartedAt: t.startedAt,
+	}
+}
+
+// UploadFile uploads the contents of r to the machine's local storage as name, in chunks over the
+// SDCP HTTP upload endpoint. Only one upload can be in progress per machine at a time, and its progress
+// can be retrieved with Transfer. If ctx is cancelled (or CancelUpload is called) before the upload completes,
+// the machine is told to terminate the file transfer.
+//
+// If r is an io.ReadSeeker the file MD5 is computed in place, otherwise r is first spooled to a temporary file.
+func (m *Machine) UploadFile(ctx context.Context, r io.Reader, name string, size int64) (*Transfer, error) {
+	if size <= 0 {
+		return nil, errors.Join(ErrUploadFailed, ErrUploadSizeMismatch)
+	}
+
+	m.transferMu.Lock()
+	if m.transfer != nil {
+		m.transferMu.Unlock()
+		return nil, errors.Join(ErrUploadFailed, ErrUploadInProgress)
+	}
+	t := &transfer{
+		uuid:      uuid.New().String(),
+		filename:  name,
+		size:      size,
+		startedAt: time.Now(),
+	}
+	ctx, t.cancel = context.WithCancel(ctx)
+	m.transfer = t
+	m.transferMu.Unlock()
+	defer func() {
+		t.cancel()
+		m.transferMu.Lock()
+		m.transfer = nil
+		m.transferMu.Unlock()
+	}()
+
+	rs, sum, cleanup, err := prepareUpload(r, size)
+	if err != nil {
+		m.logger.Error().Err(err).Str("filename", name).Msg("error preparing file upload")
+		return nil, errors.Join(ErrUploadFailed, err)
+	}
+	defer cleanup()
+	m.transferMu.Lock()
+	t.md5 = sum
+	m.transferMu.Unlock()
+
+	m.logger.Info().Str("filename", name).Str("uuid", t.uuid).Int64("size", size).Msg("starting file upload")
+	buffer := make([]byte, uploadChunkSize)
+	var n int
+	for offset := int64(0); offset < size; offset += int64(n) {
+		n, err = io.ReadFull(rs, buffer[:min(int64(uploadChunkSize), size-offset)])
+		if err != nil {
+			m.logger.Error().Err(err).Str("filename", name).Msg("error reading file chunk")
+			return nil, errors.Join(ErrUploadFailed, err)
+		}
+
+		err = m.uploadChunk(ctx, t, offset, buffer[:n])
+		if err != nil {
+			if ctx.Err() != nil {
+				m.terminateTransfer(t)
+			}
+			m.logger.Error().Err(err).Str("filename", name).Int64("offset", offset).Msg("error uploading file chunk")
+			return nil, errors.Join(ErrUploadFailed, err)
+		}
+		t.sent.Store(offset + int64(n))
+		m.logger.Debug().Str("filename", name).Int64("sent", offset+int64(n)).Int64("size", size).Msg("uploaded file chunk")
+	}
+
+	m.logger.Info().Str("filename", name).Str("uuid", t.uuid).Msg("completed file upload")
+	return t.snapshot(), nil
+}
+
+// Transfer returns the progress of the file upload currently in progress, if any
+func (m *Machine) Transfer() (*Transfer, bool) {
+	m.transferMu.Lock()
+	defer m.transferMu.Unlock()
+	if m.transfer == nil {
+		return nil, false
+	}
+	return m.transfer.snapshot(), true
+}
+
+// CancelUpload cancels the file upload currently in progress, which causes UploadFile to terminate
+// the file transfer on the machine and return
+func (m *Machine) CancelUpload() error {
+	m.transferMu.Lock()
+	defer m.transferMu.Unlock()
+	if m.transfer == nil {
+		return ErrUploadNotInProgress
+	}
+	m.transfer.cancel()
+	return nil
+}
+
+func (m *Machine) TerminateFileTransfer(ctx context.Context, uuid string, filename string) (*TerminateFileTransferResponse, error) {
+	return terminateTransferCommand.send(m, ctx, TerminateFileTransferRequest{Uuid: uuid, FileName: filename})
+}
+
+func (m *Machine) terminateTransfer(t *transfer) {
+	ctx, cancel := context.WithTimeout(m.ctx, uploadTimeout)
+	defer cancel()
+	_, err := m.TerminateFileTransfer(ctx, t.uuid, t.filename)
+	if err != nil {
+		m.logger.Warn().Err(err).Str("filename", t.filename).Msg("failed to terminate cancelled file transfer")
+		return
+	}
+	m.logger.Info().Str("filename", t.filename).Str("uuid", t.uuid).Msg("terminated cancelled file transfer")
+}
+
+func (m *Machine) uploadChunk(ctx context.Context, t *transfer, offset int64, chunk []byte) error {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	fields := [][2]string{
+		{"S-File-MD5", t.md5},
+		{"Check", "1"},
+		{"Offset", strconv.FormatInt(offset, 10)},
+		{"Uuid", t.uuid},
+		{"TotalSize", strconv.FormatInt(t.size, 10)},
+	}
+	for _, f := range fields {
+		err := w.WriteField(f[0], f[1])
+		if err != nil {
+			return err
+		}
+	}
+	part, err := w.CreateFormFile("File", t.filename)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(chunk)
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+	m.addressMu.RLock()
+	target := m.uploadURL.String()
+	m.addressMu.RUnlock()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: unexpected status code %d", ErrUploadRejected, res.StatusCode)
+	}
+
+	var u UploadFileResponse
+	err = json.NewDecoder(res.Body).Decode(&u)
+	if err != nil {
+		return err
+	}
+	if !u.Success || u.Code != uploadSuccessCode {
+		if len(u.Messages) > 0 {
+			return fmt.Errorf("%w: code %s: %s", ErrUploadRejected, u.Code, u.Messages[0].Message)
+		}
+		return fmt.Errorf("%w: code %s", ErrUploadRejected, u.Code)
+	}
+	return nil
+}
+
+// prepareUpload returns a reader positioned at the start of the file along with the file's MD5 checksum
+func prepareUpload(r io.Reader, size int64) (io.Reader, string, func(), error) {
+	h := md5.New()
+	if rs, ok := r.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		n, err := io.Copy(h, rs)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if n != size {
+			return nil, "", nil, ErrUploadSizeMismatch
+		}
+		_, err = rs.Seek(start, io.SeekStart)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return rs, hex.EncodeToString(h.Sum(nil)), func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "flux-upload-*")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cleanup := func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	n, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	if n != size {
+		cleanup()
+		return nil, "", nil, ErrUploadSizeMismatch
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	return f, hex.EncodeToString(h.Sum(nil)), cleanup, nil
+}
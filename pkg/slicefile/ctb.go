@@ -0,0 +1,226 @@
+package slicefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	ctbMagic          uint32 = 0x12FD0019
+	ctbMagicV4        uint32 = 0x12FD0086
+	ctbMagicEncrypted uint32 = 0x12FD0107
+
+	// ctbRepeat is set on a thumbnail pixel that is followed by the number of times it repeats
+	ctbRepeat = 0x20
+
+	// ctbMachineNameOffset is the offset of the machine name address within the slicer information
+	ctbMachineNameOffset = 28
+	ctbMaxMachineName    = 256
+)
+
+type ctbHeader struct {
+	Magic                 uint32
+	Version               uint32
+	BedSizeX              float32
+	BedSizeY              float32
+	BedSizeZ              float32
+	_                     [2]uint32
+	TotalHeight           float32
+	LayerHeight           float32
+	ExposureTime          float32
+	BottomExposureTime    float32
+	LightOffDelay         float32
+	BottomLayerCount      uint32
+	ResolutionX           uint32
+	ResolutionY           uint32
+	LargePreviewOffset    uint32
+	LayersOffset          uint32
+	LayerCount            uint32
+	SmallPreviewOffset    uint32
+	PrintTime             uint32
+	ProjectorType         uint32
+	PrintParametersOffset uint32
+	PrintParametersSize   uint32
+	AntiAliasLevel        uint32
+	LightPWM              uint16
+	BottomLightPWM        uint16
+	EncryptionKey         uint32
+	SlicerInfoOffset      uint32
+	SlicerInfoSize        uint32
+}
+
+type ctbPreview struct {
+	ResolutionX uint32
+	ResolutionY uint32
+	ImageOffset uint32
+	ImageLength uint32
+	_           [4]uint32
+}
+
+type ctbPrintParameters struct {
+	BottomLiftHeight    float32
+	BottomLiftSpeed     float32
+	LiftHeight          float32
+	LiftSpeed           float32
+	RetractSpeed        float32
+	Volume              float32
+	Weight              float32
+	Cost                float32
+	BottomLightOffDelay float32
+	LightOffDelay       float32
+	BottomLayerCount    uint32
+	_                   [4]uint32
+}
+
+type ctbMachineName struct {
+	Offset uint32
+	Size   uint32
+}
+
+func isCTB(magic []byte) bool {
+	return slices.Contains([]uint32{ctbMagic, ctbMagicV4, ctbMagicEncrypted}, binary.LittleEndian.Uint32(magic))
+}
+
+// parseCTB parses the header of a CTB file, which is little endian
+func parseCTB(r io.ReaderAt, size int64) (*Metadata, error) {
+	h := new(ctbHeader)
+	err := read(r, size, 0, binary.LittleEndian, h)
+	if err != nil {
+		return nil, err
+	}
+	if h.Magic == ctbMagicEncrypted {
+		// The settings of encrypted files are stored in an encrypted block
+		return nil, errors.Join(ErrUnsupportedFormat, errors.New("encrypted CTB files are not supported"))
+	}
+
+	m := &Metadata{
+		FileType:           sdcp.SupportedFileTypeCTB,
+		Version:            int(h.Version),
+		ResolutionX:        int(h.ResolutionX),
+		ResolutionY:        int(h.ResolutionY),
+		SizeX:              float64(h.BedSizeX),
+		SizeY:              float64(h.BedSizeY),
+		SizeZ:              float64(h.BedSizeZ),
+		Height:             float64(h.TotalHeight),
+		LayerCount:         int(h.LayerCount),
+		BottomLayerCount:   int(h.BottomLayerCount),
+		LayerHeight:        float64(h.LayerHeight),
+		ExposureTime:       seconds(h.ExposureTime),
+		BottomExposureTime: seconds(h.BottomExposureTime),
+		LightOffDelay:      seconds(h.LightOffDelay),
+		LightPWM:           int(h.LightPWM),
+		BottomLightPWM:     int(h.BottomLightPWM),
+		AntiAliasing:       int(h.AntiAliasLevel),
+		PrintTime:          time.Duration(h.PrintTime) * time.Second,
+	}
+
+	if h.PrintParametersOffset != 0 {
+		p := new(ctbPrintParameters)
+		err = read(r, size, int64(h.PrintParametersOffset), binary.LittleEndian, p)
+		if err != nil {
+			return nil, err
+		}
+		m.LiftHeight = float64(p.LiftHeight)
+		m.LiftSpeed = float64(p.LiftSpeed)
+		m.RetractSpeed = float64(p.RetractSpeed)
+		m.Volume = float64(p.Volume)
+		m.Weight = float64(p.Weight)
+	}
+
+	if h.Version >= 3 && h.SlicerInfoOffset != 0 {
+		n := new(ctbMachineName)
+		err = read(r, size, int64(h.SlicerInfoOffset)+ctbMachineNameOffset, binary.LittleEndian, n)
+		if err != nil {
+			return nil, err
+		}
+		if n.Offset != 0 && n.Size > 0 && n.Size <= ctbMaxMachineName {
+			name := make([]byte, n.Size)
+			err = read(r, size, int64(n.Offset), binary.LittleEndian, name)
+			if err != nil {
+				return nil, err
+			}
+			m.MachineName = str(name)
+		}
+	}
+
+	for _, offset := range []uint32{h.LargePreviewOffset, h.SmallPreviewOffset} {
+		if offset == 0 {
+			continue
+		}
+		t, err := ctbThumbnail(r, size, int64(offset))
+		if err != nil {
+			return nil, err
+		}
+		m.Thumbnails = append(m.Thumbnails, *t)
+	}
+
+	return m, nil
+}
+
+// ctbThumbnail decodes the run-length encoded RGB555 thumbnail with the preview header at offset
+func ctbThumbnail(r io.ReaderAt, size int64, offset int64) (*Thumbnail, error) {
+	p := new(ctbPreview)
+	err := read(r, size, offset, binary.LittleEndian, p)
+	if err != nil {
+		return nil, err
+	}
+	if p.ResolutionX == 0 || p.ResolutionY == 0 || p.ResolutionX > maxThumbnailSize || p.ResolutionY > maxThumbnailSize {
+		return nil, ErrInvalidThumbnail
+	}
+	// The image is checked to lie within the file before it is allocated, and can be at most 4 bytes per pixel
+	if int64(p.ImageOffset)+int64(p.ImageLength) > size {
+		return nil, ErrInvalidHeader
+	}
+	if uint64(p.ImageLength) > uint64(p.ResolutionX)*uint64(p.ResolutionY)*4 {
+		return nil, ErrInvalidThumbnail
+	}
+	data := make([]byte, p.ImageLength)
+	err = read(r, size, int64(p.ImageOffset), binary.LittleEndian, data)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, int(p.ResolutionX), int(p.ResolutionY)))
+	pixels := int(p.ResolutionX * p.ResolutionY)
+	pixel := 0
+	for i := 0; i+1 < len(data) && pixel < pixels; i += 2 {
+		v := binary.LittleEndian.Uint16(data[i:])
+		repeat := 1
+		if v&ctbRepeat != 0 {
+			if i+3 >= len(data) {
+				return nil, ErrInvalidThumbnail
+			}
+			i += 2
+			repeat += int(binary.LittleEndian.Uint16(data[i:]) & 0x0FFF)
+		}
+		c := color.RGBA{R: rgb(v>>11, 5), G: rgb(v>>6&0x1F, 5), B: rgb(v&0x1F, 5), A: 0xFF}
+		for ; repeat > 0 && pixel < pixels; repeat-- {
+			img.SetRGBA(pixel%int(p.ResolutionX), pixel/int(p.ResolutionX), c)
+			pixel++
+		}
+	}
+	if pixel != pixels {
+		return nil, ErrInvalidThumbnail
+	}
+	return &Thumbnail{Image: img}, nil
+}
+
+// read decodes v from r at offset, checking that it lies within the file
+func read(r io.ReaderAt, size int64, offset int64, order binary.ByteOrder, v any) error {
+	n := int64(binary.Size(v))
+	if offset < 0 || n < 0 || offset+n > size {
+		return ErrInvalidHeader
+	}
+	err := binary.Read(io.NewSectionReader(r, offset, n), order, v)
+	if err != nil {
+		return errors.Join(ErrInvalidHeader, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package slicefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	gooSmallPreview = 116
+	gooBigPreview   = 290
+)
+
+var (
+	gooMagic = [8]byte{0x07, 0x00, 0x00, 0x00, 'D', 'L', 'P', 0x00}
+)
+
+type gooHeader struct {
+	Version                  [4]byte
+	Magic                    [8]byte
+	SoftwareName             [32]byte
+	SoftwareVersion          [24]byte
+	FileCreateTime           [24]byte
+	MachineName              [32]byte
+	MachineType              [32]byte
+	ProfileName              [32]byte
+	AntiAliasingLevel        uint16
+	GreyLevel                uint16
+	BlurLevel                uint16
+	SmallPreview             [gooSmallPreview * gooSmallPreview]uint16
+	SmallPreviewDelimiter    [2]byte
+	BigPreview               [gooBigPreview * gooBigPreview]uint16
+	BigPreviewDelimiter      [2]byte
+	LayerCount               uint32
+	ResolutionX              uint16
+	ResolutionY              uint16
+	MirrorX                  bool
+	MirrorY                  bool
+	DisplayWidth             float32
+	DisplayHeight            float32
+	MachineZ                 float32
+	LayerHeight              float32
+	ExposureTime             float32
+	DelayMode                bool
+	LightOffDelay            float32
+	BottomWaitTimeAfterCure  float32
+	BottomWaitTimeAfterLift  float32
+	BottomWaitTimeBeforeCure float32
+	WaitTimeAfterCure        float32
+	WaitTimeAfterLift        float32
+	WaitTimeBeforeCure       float32
+	BottomExposureTime       float32
+	BottomLayerCount         uint32
+	BottomLiftHeight         float32
+	BottomLiftSpeed          float32
+	LiftHeight               float32
+	LiftSpeed                float32
+	BottomRetractHeight      float32
+	BottomRetractSpeed       float32
+	RetractHeight            float32
+	RetractSpeed             float32
+	BottomLiftHeight2        float32
+	BottomLiftSpeed2         float32
+	LiftHeight2              float32
+	LiftSpeed2               float32
+	BottomRetractHeight2     float32
+	BottomRetractSpeed2      float32
+	RetractHeight2           float32
+	RetractSpeed2            float32
+	BottomLightPWM           uint16
+	LightPWM                 uint16
+	PerLayerSettings         bool
+	PrintTime                uint32
+	Volume                   float32
+	Weight                   float32
+	Cost                     float32
+	PriceUnit                [8]byte
+	LayersOffset             uint32
+	GreyScaleLevel           uint8
+	TransitionLayers         uint16
+}
+
+// parseGOO parses the header of a GOO file, which is big endian
+func parseGOO(r io.ReaderAt, size int64) (*Metadata, error) {
+	h := new(gooHeader)
+	err := read(r, size, 0, binary.BigEndian, h)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := strconv.ParseFloat(strings.TrimPrefix(str(h.Version[:]), "V"), 64)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidHeader, err)
+	}
+
+	return &Metadata{
+		FileType:           sdcp.SupportedFileTypeGOO,
+		Version:            int(version),
+		MachineName:        str(h.MachineName[:]),
+		ResolutionX:        int(h.ResolutionX),
+		ResolutionY:        int(h.ResolutionY),
+		SizeX:              float64(h.DisplayWidth),
+		SizeY:              float64(h.DisplayHeight),
+		SizeZ:              float64(h.MachineZ),
+		Height:             float64(h.LayerCount) * float64(h.LayerHeight),
+		LayerCount:         int(h.LayerCount),
+		BottomLayerCount:   int(h.BottomLayerCount),
+		LayerHeight:        float64(h.LayerHeight),
+		ExposureTime:       seconds(h.ExposureTime),
+		BottomExposureTime: seconds(h.BottomExposureTime),
+		LightOffDelay:      seconds(h.LightOffDelay),
+		LightPWM:           int(h.LightPWM),
+		BottomLightPWM:     int(h.BottomLightPWM),
+		LiftHeight:         float64(h.LiftHeight),
+		LiftSpeed:          float64(h.LiftSpeed),
+		RetractSpeed:       float64(h.RetractSpeed),
+		AntiAliasing:       int(h.AntiAliasingLevel),
+		PrintTime:          time.Duration(h.PrintTime) * time.Second,
+		Volume:             float64(h.Volume),
+		Weight:             float64(h.Weight),
+		Thumbnails: []Thumbnail{
+			gooThumbnail(h.BigPreview[:], gooBigPreview),
+			gooThumbnail(h.SmallPreview[:], gooSmallPreview),
+		},
+	}, nil
+}
+
+// gooThumbnail decodes a square RGB565 thumbnail
+func gooThumbnail(pixels []uint16, size int) Thumbnail {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i, v := range pixels {
+		img.SetRGBA(i%size, i/size, color.RGBA{R: rgb(v>>11, 5), G: rgb(v>>5&0x3F, 6), B: rgb(v&0x1F, 5), A: 0xFF})
+	}
+	return Thumbnail{Image: img}
+}
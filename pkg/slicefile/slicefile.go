@@ -0,0 +1,154 @@
+// Package slicefile parses the headers of the sliced files printed by SDCP machines, extracting
+// their print settings and preview thumbnails without reading their layers.
+package slicefile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+var (
+	ErrParseFailed       = errors.New("failed to parse sliced file")
+	ErrUnknownFormat     = errors.New("unknown sliced file format")
+	ErrUnsupportedFormat = errors.New("unsupported sliced file format")
+	ErrInvalidHeader     = errors.New("invalid sliced file header")
+	ErrInvalidThumbnail  = errors.New("invalid sliced file thumbnail")
+)
+
+const (
+	// maxThumbnailSize is the largest width or height of a thumbnail that is decoded
+	maxThumbnailSize = 4096
+)
+
+// Metadata is the information stored in the header of a sliced file.
+// Lengths are in millimeters, speeds in millimeters per minute and volumes in milliliters.
+type Metadata struct {
+	FileType    sdcp.SupportedFileType
+	Version     int
+	MachineName string // Name of the machine the file was sliced for, if recorded by the slicer
+
+	ResolutionX int
+	ResolutionY int
+	SizeX       float64 // Width of the display
+	SizeY       float64 // Height of the display
+	SizeZ       float64 // Height of the build volume
+	Height      float64 // Height of the printed model
+
+	LayerCount         int
+	BottomLayerCount   int
+	LayerHeight        float64
+	ExposureTime       time.Duration
+	BottomExposureTime time.Duration
+	LightOffDelay      time.Duration
+	LightPWM           int
+	BottomLightPWM     int
+	LiftHeight         float64
+	LiftSpeed          float64
+	RetractSpeed       float64
+	AntiAliasing       int
+
+	PrintTime time.Duration // Estimated by the slicer
+	Volume    float64
+	Weight    float64 // In grams
+
+	Thumbnails []Thumbnail // Largest first
+}
+
+// Resolution returns the resolution of the file in the format used by Attributes.Resolution
+func (m *Metadata) Resolution() string {
+	return fmt.Sprintf("%dx%d", m.ResolutionX, m.ResolutionY)
+}
+
+// Thumbnail is a preview image of the sliced model
+type Thumbnail struct {
+	Image *image.RGBA
+}
+
+func (t *Thumbnail) Width() int {
+	return t.Image.Rect.Dx()
+}
+
+func (t *Thumbnail) Height() int {
+	return t.Image.Rect.Dy()
+}
+
+// WritePNG encodes the thumbnail as a PNG to w
+func (t *Thumbnail) WritePNG(w io.Writer) error {
+	return png.Encode(w, t.Image)
+}
+
+// PNG returns the thumbnail encoded as a PNG
+func (t *Thumbnail) PNG() ([]byte, error) {
+	var buf bytes.Buffer
+	err := t.WritePNG(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse detects the format of the sliced file of the given size read from r, and parses its header
+func Parse(r io.ReaderAt, size int64) (*Metadata, error) {
+	magic := make([]byte, len(gooMagic)+4)
+	_, err := r.ReadAt(magic, 0)
+	if err != nil {
+		return nil, errors.Join(ErrParseFailed, ErrInvalidHeader, err)
+	}
+
+	var m *Metadata
+	switch {
+	case bytes.Equal(magic[4:], gooMagic[:]):
+		m, err = parseGOO(r, size)
+	case isCTB(magic):
+		m, err = parseCTB(r, size)
+	default:
+		return nil, errors.Join(ErrParseFailed, ErrUnknownFormat)
+	}
+	if err != nil {
+		return nil, errors.Join(ErrParseFailed, err)
+	}
+	return m, nil
+}
+
+// ParseFile parses the header of the sliced file at path
+func ParseFile(path string) (*Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Join(ErrParseFailed, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Join(ErrParseFailed, err)
+	}
+	return Parse(f, info.Size())
+}
+
+// seconds converts a duration in seconds stored in a header to a time.Duration
+func seconds(s float32) time.Duration {
+	return time.Duration(float64(s) * float64(time.Second))
+}
+
+// str converts a NUL padded string stored in a header to a string
+func str(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(bytes.TrimSpace(b))
+}
+
+// rgb returns the 8-bit value of a color channel with the given number of bits
+func rgb(v uint16, bits uint) uint8 {
+	return uint8(uint32(v) * 255 / (1<<bits - 1))
+}
@@ -0,0 +1,153 @@
+package slicefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+func TestCTB(t *testing.T) {
+	const (
+		parametersOffset = 112
+		slicerOffset     = parametersOffset + 60
+		nameOffset       = slicerOffset + ctbMachineNameOffset + 8
+		name             = "ELEGOO Saturn 4 Ultra"
+		previewOffset    = nameOffset + len(name)
+		imageOffset      = previewOffset + 32
+	)
+	red, blue := uint16(0x1F<<11), uint16(0x1F)
+	image := []uint16{red | ctbRepeat, 4, blue, blue, blue}
+
+	var buf bytes.Buffer
+	write := func(v any) {
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, v))
+	}
+	write(&ctbHeader{
+		Magic:                 ctbMagicV4,
+		Version:               4,
+		BedSizeX:              218.88,
+		BedSizeY:              122.88,
+		BedSizeZ:              220,
+		TotalHeight:           10,
+		LayerHeight:           0.05,
+		ExposureTime:          2.5,
+		BottomExposureTime:    30,
+		BottomLayerCount:      5,
+		ResolutionX:           11520,
+		ResolutionY:           5120,
+		LargePreviewOffset:    uint32(previewOffset),
+		LayerCount:            200,
+		PrintTime:             3600,
+		PrintParametersOffset: parametersOffset,
+		PrintParametersSize:   60,
+		AntiAliasLevel:        4,
+		LightPWM:              255,
+		BottomLightPWM:        255,
+		SlicerInfoOffset:      slicerOffset,
+		SlicerInfoSize:        ctbMachineNameOffset + 8,
+	})
+	write(&ctbPrintParameters{LiftHeight: 6, LiftSpeed: 120, RetractSpeed: 180, Volume: 12.5, Weight: 13.75})
+	write(make([]byte, ctbMachineNameOffset))
+	write(&ctbMachineName{Offset: nameOffset, Size: uint32(len(name))})
+	write([]byte(name))
+	write(&ctbPreview{ResolutionX: 4, ResolutionY: 2, ImageOffset: uint32(imageOffset), ImageLength: uint32(len(image) * 2)})
+	write(image)
+
+	m, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, sdcp.SupportedFileTypeCTB, m.FileType)
+	require.Equal(t, 4, m.Version)
+	require.Equal(t, name, m.MachineName)
+	require.Equal(t, "11520x5120", m.Resolution())
+	require.Equal(t, 200, m.LayerCount)
+	require.Equal(t, 5, m.BottomLayerCount)
+	require.InDelta(t, 0.05, m.LayerHeight, 1e-6)
+	require.Equal(t, 2500*time.Millisecond, m.ExposureTime)
+	require.Equal(t, 30*time.Second, m.BottomExposureTime)
+	require.Equal(t, time.Hour, m.PrintTime)
+	require.InDelta(t, 12.5, m.Volume, 1e-6)
+	require.InDelta(t, 120, m.LiftSpeed, 1e-6)
+
+	require.Len(t, m.Thumbnails, 1)
+	thumbnail := m.Thumbnails[0]
+	require.Equal(t, 4, thumbnail.Width())
+	require.Equal(t, 2, thumbnail.Height())
+	require.Equal(t, color.RGBA{R: 0xFF, A: 0xFF}, thumbnail.Image.RGBAAt(0, 1))
+	require.Equal(t, color.RGBA{B: 0xFF, A: 0xFF}, thumbnail.Image.RGBAAt(3, 1))
+
+	data, err := thumbnail.PNG()
+	require.NoError(t, err)
+	decoded, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, thumbnail.Image.Bounds(), decoded.Bounds())
+
+	_, err = Parse(bytes.NewReader(buf.Bytes()), int64(previewOffset+16))
+	require.ErrorIs(t, err, ErrInvalidHeader)
+
+	// Thumbnails longer than the file, or than the largest encoding of their resolution, are rejected before
+	// they are read
+	data = bytes.Clone(buf.Bytes())
+	binary.LittleEndian.PutUint32(data[previewOffset+12:], 0xFFFFFFFF)
+	_, err = Parse(bytes.NewReader(data), int64(len(data)))
+	require.ErrorIs(t, err, ErrInvalidHeader)
+	data = bytes.Clone(buf.Bytes())
+	binary.LittleEndian.PutUint32(data[previewOffset:], 1)
+	binary.LittleEndian.PutUint32(data[previewOffset+4:], 1)
+	_, err = Parse(bytes.NewReader(data), int64(len(data)))
+	require.ErrorIs(t, err, ErrInvalidThumbnail)
+}
+
+func TestGOO(t *testing.T) {
+	h := &gooHeader{
+		Magic:              gooMagic,
+		LayerCount:         100,
+		ResolutionX:        11520,
+		ResolutionY:        5120,
+		DisplayWidth:       218.88,
+		DisplayHeight:      122.88,
+		MachineZ:           220,
+		LayerHeight:        0.05,
+		ExposureTime:       2,
+		BottomExposureTime: 25,
+		BottomLayerCount:   4,
+		LiftHeight:         5,
+		PrintTime:          1800,
+		Volume:             8,
+	}
+	copy(h.Version[:], "V3.0")
+	copy(h.MachineName[:], "ELEGOO Saturn 3 Ultra")
+	for i := range h.BigPreview {
+		h.BigPreview[i] = 0x3F << 5
+	}
+	h.SmallPreview[0] = 0x1F << 11
+
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.BigEndian, h))
+
+	m, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, sdcp.SupportedFileTypeGOO, m.FileType)
+	require.Equal(t, 3, m.Version)
+	require.Equal(t, "ELEGOO Saturn 3 Ultra", m.MachineName)
+	require.Equal(t, "11520x5120", m.Resolution())
+	require.Equal(t, 100, m.LayerCount)
+	require.InDelta(t, 5, m.Height, 1e-4)
+	require.Equal(t, 25*time.Second, m.BottomExposureTime)
+	require.Equal(t, 30*time.Minute, m.PrintTime)
+
+	require.Len(t, m.Thumbnails, 2)
+	require.Equal(t, gooBigPreview, m.Thumbnails[0].Width())
+	require.Equal(t, color.RGBA{G: 0xFF, A: 0xFF}, m.Thumbnails[0].Image.RGBAAt(10, 10))
+	require.Equal(t, gooSmallPreview, m.Thumbnails[1].Height())
+	require.Equal(t, color.RGBA{R: 0xFF, A: 0xFF}, m.Thumbnails[1].Image.RGBAAt(0, 0))
+
+	_, err = Parse(bytes.NewReader([]byte("not a sliced file")), 17)
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
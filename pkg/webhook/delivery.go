@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	HeaderEvent     = "X-Flux-Event"
+	HeaderDelivery  = "X-Flux-Delivery"
+	HeaderSignature = "X-Flux-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Payload is the JSON body sent to a webhook
+type Payload struct {
+	ID          string               `json:"id"`
+	Event       Event                `json:"event"`
+	Time        int64                `json:"time"`
+	MachineID   string               `json:"machine_id"`
+	MachineName string               `json:"machine_name,omitempty"`
+	State       sdcp.ConnectionState `json:"state,omitempty"`
+	Print       *Print               `json:"print,omitempty"`
+}
+
+type Print struct {
+	TaskID       string         `json:"task_id"`
+	Filename     string         `json:"filename"`
+	CurrentLayer int            `json:"current_layer"`
+	TotalLayer   int            `json:"total_layer"`
+	TaskError    sdcp.TaskError `json:"task_error,omitempty"`
+	Reason       string         `json:"reason,omitempty"`
+}
+
+// Delivery is the outcome of sending a payload to a webhook
+type Delivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	Event      Event     `json:"event"`
+	Time       time.Time `json:"time"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Succeeded  bool      `json:"succeeded"`
+}
+
+// Sign returns the value of the signature header of a payload, the hex encoded HMAC-SHA256 of body
+// keyed with the secret of the webhook
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature is the signature of body keyed with secret
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func (w *Manager) payload(event Event, e sdcp.Event, m *sdcp.Machine) *Payload {
+	p := &Payload{
+		ID:        uuid.New().String(),
+		Event:     event,
+		Time:      e.Time.UnixMilli(),
+		MachineID: e.MachineID,
+		State:     e.State,
+	}
+	if m != nil {
+		p.MachineName = m.Label()
+		if p.MachineName == "" {
+			p.MachineName = m.Attributes().MachineName
+		}
+	}
+	if e.Print != nil {
+		p.Print = &Print{
+			TaskID:       e.Print.TaskID,
+			Filename:     e.Print.Filename,
+			CurrentLayer: e.Print.CurrentLayer,
+			TotalLayer:   e.Print.TotalLayer,
+		}
+	}
+	return p
+}
+
+// deliver sends the payload to the webhook, retrying with exponential backoff until it is accepted,
+// the webhook rejects it, or it runs out of attempts
+func (w *Manager) deliver(h Webhook, p *Payload) {
+	defer w.wg.Done()
+	d := Delivery{
+		ID:        p.ID,
+		WebhookID: h.ID,
+		Event:     p.Event,
+		Time:      time.Now(),
+	}
+	defer func() {
+		w.record(d)
+	}()
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		d.Error = err.Error()
+		return
+	}
+
+	backoff := w.options.Backoff
+	for d.Attempts < w.options.MaxAttempts {
+		if d.Attempts > 0 {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		d.Attempts++
+
+		var retry bool
+		d.StatusCode, retry, err = w.send(h, p, body)
+		if err == nil {
+			d.Error = ""
+			d.Succeeded = true
+			return
+		}
+		d.Error = err.Error()
+		w.logger.Warn().Err(err).Str("webhook", h.ID).Int("attempt", d.Attempts).Msgf("failed to deliver %s", p.Event)
+		if !retry {
+			return
+		}
+	}
+}
+
+// send makes a single attempt at delivering the payload, returning whether a failed attempt should be retried
+func (w *Manager) send(h Webhook, p *Payload, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(p.Event))
+	req.Header.Set(HeaderDelivery, p.ID)
+	req.Header.Set(HeaderSignature, Sign(h.Secret, body))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, w.ctx.Err() == nil, err
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestTimeout
+	return res.StatusCode, retry, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+}
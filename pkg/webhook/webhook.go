@@ -0,0 +1,391 @@
+// Package webhook notifies HTTP endpoints of print lifecycle and machine connection events,
+// signing every payload with a secret shared with the endpoint.
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/loopholelabs/logging/types"
+
+	"github.com/shivanshvij/flux/internal/utils"
+	"github.com/shivanshvij/flux/pkg/sdcp"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = time.Second
+	DefaultTimeout     = 10 * time.Second
+
+	maxDeliveries  = 100
+	secretSize     = 32
+	resolveTimeout = 30 * time.Second
+)
+
+var (
+	ErrReadFailed      = errors.New("failed to read webhooks file")
+	ErrWriteFailed     = errors.New("failed to write webhooks file")
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidURL      = errors.New("invalid webhook url")
+	ErrInvalidEvent    = errors.New("invalid webhook event")
+	ErrSecretFailed    = errors.New("failed to generate webhook secret")
+)
+
+type Event string
+
+const (
+	EventPrintStarted   Event = "print.started"
+	EventPrintPaused    Event = "print.paused"
+	EventPrintResumed   Event = "print.resumed"
+	EventPrintCompleted Event = "print.completed"
+	EventPrintStopped   Event = "print.stopped" // Stopped by a user
+	EventPrintFailed    Event = "print.failed"  // Stopped by the machine because of a task error
+	EventMachineOnline  Event = "machine.online"
+	EventMachineOffline Event = "machine.offline"
+)
+
+var Events = []Event{
+	EventPrintStarted,
+	EventPrintPaused,
+	EventPrintResumed,
+	EventPrintCompleted,
+	EventPrintStopped,
+	EventPrintFailed,
+	EventMachineOnline,
+	EventMachineOffline,
+}
+
+func (e Event) Valid() bool {
+	return slices.Contains(Events, e)
+}
+
+// Webhook is an HTTP endpoint that is sent the events it subscribes to, or every event if Events is empty
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []Event   `json:"events"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w *Webhook) subscribed(e Event) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, e)
+}
+
+type Options struct {
+	MaxAttempts int           // Number of times a delivery is attempted before it is given up on
+	Backoff     time.Duration // Delay before the first retry of a delivery, doubled after every attempt
+	Timeout     time.Duration // Timeout of a single attempt
+}
+
+func (o *Options) defaults() {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultMaxAttempts
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultBackoff
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+}
+
+// Manager keeps the configured webhooks in a local file, and delivers the events of every machine
+// to the webhooks that subscribe to them, keeping a log of recent deliveries
+type Manager struct {
+	logger  types.Logger
+	sdcp    *sdcp.SDCP
+	path    string
+	options Options
+	client  *http.Client
+
+	mu         sync.Mutex
+	webhooks   []*Webhook
+	deliveries []Delivery
+
+	states map[string]sdcp.ConnectionState // Last online or offline state of every machine, only used by observe
+
+	subscription *sdcp.Subscription
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+func New(s *sdcp.SDCP, path string, options Options, logger types.Logger) (*Manager, error) {
+	options.defaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Manager{
+		logger:  logger.SubLogger("webhook"),
+		sdcp:    s,
+		path:    path,
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout},
+		states:  make(map[string]sdcp.ConnectionState),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			cancel()
+			return nil, errors.Join(ErrReadFailed, err)
+		}
+	} else {
+		err = json.Unmarshal(data, &w.webhooks)
+		if err != nil {
+			cancel()
+			return nil, errors.Join(ErrReadFailed, err)
+		}
+	}
+
+	return w, nil
+}
+
+// Start begins delivering the events of every machine
+func (w *Manager) Start() {
+	w.subscription = w.sdcp.Subscribe(sdcp.DefaultSubscriptionBuffer)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for e := range w.subscription.C {
+			w.observe(e)
+		}
+	}()
+}
+
+// Stop stops delivering events, abandoning deliveries that are being retried
+func (w *Manager) Stop() {
+	if w.subscription != nil {
+		w.subscription.Close()
+	}
+	w.cancel()
+	w.wg.Wait()
+}
+
+// Webhooks returns every configured webhook
+func (w *Manager) Webhooks() []Webhook {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhooks := make([]Webhook, len(w.webhooks))
+	for i, h := range w.webhooks {
+		webhooks[i] = *h
+	}
+	return webhooks
+}
+
+func (w *Manager) Webhook(id string) (*Webhook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := w.find(id)
+	if i < 0 {
+		return nil, ErrWebhookNotFound
+	}
+	h := *w.webhooks[i]
+	return &h, nil
+}
+
+// Create adds a webhook that is sent the given events, or every event if none are given. A random
+// secret is generated if secret is empty.
+func (w *Manager) Create(rawURL string, events []Event, secret string) (*Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	for _, e := range events {
+		if !e.Valid() {
+			return nil, errors.Join(ErrInvalidEvent, errors.New(string(e)))
+		}
+	}
+	if secret == "" {
+		b := make([]byte, secretSize)
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, errors.Join(ErrSecretFailed, err)
+		}
+		secret = hex.EncodeToString(b)
+	}
+	events = slices.Clone(events)
+	slices.Sort(events)
+
+	h := &Webhook{
+		ID:        uuid.New().String(),
+		URL:       u.String(),
+		Events:    slices.Compact(events),
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.webhooks = append(w.webhooks, h)
+	err = w.write()
+	if err != nil {
+		w.webhooks = w.webhooks[:len(w.webhooks)-1]
+		return nil, err
+	}
+	w.logger.Info().Str("webhook", h.ID).Msgf("created webhook for %s", h.URL)
+	c := *h
+	return &c, nil
+}
+
+// Delete removes a webhook, deliveries to it that are in progress are still completed
+func (w *Manager) Delete(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := w.find(id)
+	if i < 0 {
+		return ErrWebhookNotFound
+	}
+	h := w.webhooks[i]
+	w.webhooks = slices.Delete(w.webhooks, i, i+1)
+	err := w.write()
+	if err != nil {
+		w.webhooks = slices.Insert(w.webhooks, i, h)
+		return err
+	}
+	w.logger.Info().Str("webhook", id).Msg("deleted webhook")
+	return nil
+}
+
+// Deliveries returns the most recent deliveries to a webhook, oldest first
+func (w *Manager) Deliveries(id string) ([]Delivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.find(id) < 0 {
+		return nil, ErrWebhookNotFound
+	}
+	var deliveries []Delivery
+	for _, d := range w.deliveries {
+		if d.WebhookID == id {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// observe converts an event of a machine into the webhook events it represents
+func (w *Manager) observe(e sdcp.Event) {
+	m, _ := w.sdcp.GetMachine(e.MachineID)
+	switch e.Type {
+	case sdcp.EventTypeState:
+		if !w.transition(e.MachineID, e.State) {
+			return
+		}
+		switch e.State {
+		case sdcp.ConnectionStateOnline:
+			w.dispatch(w.payload(EventMachineOnline, e, m))
+		case sdcp.ConnectionStateOffline:
+			w.dispatch(w.payload(EventMachineOffline, e, m))
+		}
+	case sdcp.EventTypePrint:
+		if e.Print == nil {
+			return
+		}
+		switch e.Print.Type {
+		case sdcp.PrintEventTypeStarted:
+			w.dispatch(w.payload(EventPrintStarted, e, m))
+		case sdcp.PrintEventTypePaused:
+			w.dispatch(w.payload(EventPrintPaused, e, m))
+		case sdcp.PrintEventTypeResumed:
+			w.dispatch(w.payload(EventPrintResumed, e, m))
+		case sdcp.PrintEventTypeCompleted:
+			w.dispatch(w.payload(EventPrintCompleted, e, m))
+		case sdcp.PrintEventTypeStopped:
+			if m == nil {
+				w.dispatch(w.payload(EventPrintStopped, e, m))
+				return
+			}
+			// The status of a stopped print does not say why it stopped, only its task details do
+			w.wg.Add(1)
+			go w.resolve(w.payload(EventPrintStopped, e, m), m)
+		}
+	}
+}
+
+// transition records the connection state of a machine, returning true if it is a transition into or
+// out of ConnectionStateOnline. Machines that cannot be reached are marked connecting and then offline on
+// every attempt to reconnect, which must not be delivered as the machine going offline again.
+func (w *Manager) transition(id string, state sdcp.ConnectionState) bool {
+	if state == sdcp.ConnectionStateConnecting {
+		return false
+	}
+	previous := w.states[id]
+	w.states[id] = state
+	if state == sdcp.ConnectionStateOnline {
+		return previous != sdcp.ConnectionStateOnline
+	}
+	return previous == sdcp.ConnectionStateOnline
+}
+
+// resolve dispatches a stopped print as failed if the machine recorded a task error for it
+func (w *Manager) resolve(p *Payload, m *sdcp.Machine) {
+	defer w.wg.Done()
+	ctx, cancel := context.WithTimeout(w.ctx, resolveTimeout)
+	tasks, err := m.TaskDetails(ctx, p.Print.TaskID)
+	cancel()
+	if err != nil {
+		w.logger.Warn().Err(err).Str("machine", m.ID()).Msg("failed to retrieve details of stopped print")
+	} else if len(tasks) > 0 && tasks[0].TaskStatus == sdcp.TaskStatusExceptional {
+		p.Event = EventPrintFailed
+		p.Print.TaskError = tasks[0].ErrorStatusReason
+		p.Print.Reason = tasks[0].ErrorStatusReason.String()
+	}
+	w.dispatch(p)
+}
+
+// dispatch delivers the payload to every webhook that subscribes to its event
+func (w *Manager) dispatch(p *Payload) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx.Err() != nil {
+		return
+	}
+	for _, h := range w.webhooks {
+		if h.subscribed(p.Event) {
+			w.wg.Add(1)
+			go w.deliver(*h, p)
+		}
+	}
+}
+
+func (w *Manager) record(d Delivery) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deliveries = append(w.deliveries, d)
+	if len(w.deliveries) > maxDeliveries {
+		w.deliveries = w.deliveries[len(w.deliveries)-maxDeliveries:]
+	}
+}
+
+func (w *Manager) find(id string) int {
+	return slices.IndexFunc(w.webhooks, func(h *Webhook) bool {
+		return h.ID == id
+	})
+}
+
+func (w *Manager) write() error {
+	data, err := json.Marshal(w.webhooks)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	err = utils.WriteFileAtomic(w.path, data)
+	if err != nil {
+		return errors.Join(ErrWriteFailed, err)
+	}
+	return nil
+}
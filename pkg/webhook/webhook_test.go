@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loopholelabs/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivanshvij/flux/pkg/sdcp"
+	"github.com/shivanshvij/flux/pkg/sdcp/sdcptest"
+)
+
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	payloads []Payload
+}
+
+func (r *receiver) events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]Event, 0, len(r.payloads))
+	for _, p := range r.payloads {
+		events = append(events, p.Event)
+	}
+	return events
+}
+
+func (r *receiver) last() Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.payloads[len(r.payloads)-1]
+}
+
+func TestWebhook(t *testing.T) {
+	logger := logging.Test(t, logging.Slog, t.Name())
+	s := sdcp.New(logger, nil)
+	t.Cleanup(s.Close)
+
+	// Prints never progress on their own, so the test decides when they complete
+	printer, err := sdcptest.NewPrinter(sdcptest.Config{LayerTime: time.Hour})
+	require.NoError(t, err)
+	t.Cleanup(printer.Close)
+	printer.AddFile(sdcp.LocalPath("model.ctb"), 1024, 5)
+
+	const secret = "secret"
+	r := &receiver{failures: 1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.True(t, Verify(secret, body, req.Header.Get(HeaderSignature)))
+		require.NotEmpty(t, req.Header.Get(HeaderDelivery))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var p Payload
+		require.NoError(t, json.Unmarshal(body, &p))
+		require.Equal(t, string(p.Event), req.Header.Get(HeaderEvent))
+		r.payloads = append(r.payloads, p)
+	}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	w, err := New(s, path, Options{Backoff: 10 * time.Millisecond}, logger)
+	require.NoError(t, err)
+	w.Start()
+	t.Cleanup(w.Stop)
+
+	_, err = w.Create("ftp://example.com", nil, "")
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = w.Create(server.URL, []Event{"print.unknown"}, "")
+	require.ErrorIs(t, err, ErrInvalidEvent)
+	h, err := w.Create(server.URL, []Event{EventPrintStarted, EventPrintPaused, EventPrintResumed, EventPrintCompleted, EventPrintFailed}, secret)
+	require.NoError(t, err)
+	generated, err := w.Create(server.URL+"/unknown", []Event{EventMachineOffline}, "")
+	require.NoError(t, err)
+	require.Len(t, generated.Secret, secretSize*2)
+
+	require.NoError(t, s.Register(printer.Registration()))
+	m, ok := s.GetMachine(printer.ID())
+	require.True(t, ok)
+	require.Eventually(t, func() bool {
+		return m.State() == sdcp.ConnectionStateOnline
+	}, 10*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	_, err = m.StartPrint(ctx, "model.ctb", 0)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(r.events()) == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	_, err = m.PausePrint(ctx)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(r.events()) == 2
+	}, 10*time.Second, 10*time.Millisecond)
+	_, err = m.ResumePrint(ctx)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(r.events()) == 3
+	}, 10*time.Second, 10*time.Millisecond)
+	require.True(t, printer.CompletePrint())
+	require.Eventually(t, func() bool {
+		return len(r.events()) == 4
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, []Event{EventPrintStarted, EventPrintPaused, EventPrintResumed, EventPrintCompleted}, r.events())
+
+	completed := r.last()
+	require.Equal(t, printer.ID(), completed.MachineID)
+	require.Equal(t, string(sdcp.LocalPath("model.ctb")), completed.Print.Filename)
+	require.Equal(t, 5, completed.Print.TotalLayer)
+
+	deliveries, err := w.Deliveries(h.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 4)
+	require.Equal(t, EventPrintStarted, deliveries[0].Event)
+	require.Equal(t, 2, deliveries[0].Attempts)
+	require.True(t, deliveries[0].Succeeded)
+
+	_, err = m.StartPrint(ctx, "model.ctb", 0)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(r.events()) == 5
+	}, 10*time.Second, 10*time.Millisecond)
+	require.True(t, printer.FailPrint(sdcp.TaskErrorResinLack))
+	require.Eventually(t, func() bool {
+		return len(r.events()) == 6
+	}, 10*time.Second, 10*time.Millisecond)
+	failed := r.last()
+	require.Equal(t, EventPrintFailed, failed.Event)
+	require.Equal(t, sdcp.TaskErrorResinLack, failed.Print.TaskError)
+
+	// The generated webhook has no receiver, so its deliveries are rejected without being retried
+	require.NoError(t, w.Delete(h.ID))
+	_, err = w.Deliveries(h.ID)
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+	printer.Disconnect()
+	require.Eventually(t, func() bool {
+		deliveries, err = w.Deliveries(generated.ID)
+		require.NoError(t, err)
+		return len(deliveries) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, EventMachineOffline, deliveries[0].Event)
+	require.Equal(t, 1, deliveries[0].Attempts)
+	require.False(t, deliveries[0].Succeeded)
+
+	restored, err := New(s, path, Options{}, logger)
+	require.NoError(t, err)
+	require.Len(t, restored.Webhooks(), 1)
+	require.Equal(t, generated.Secret, restored.Webhooks()[0].Secret)
+
+	// Failed attempts to reconnect to an offline machine are not delivered as the machine going offline again
+	id := printer.ID()
+	require.False(t, restored.transition(id, sdcp.ConnectionStateOffline))
+	require.True(t, restored.transition(id, sdcp.ConnectionStateOnline))
+	require.False(t, restored.transition(id, sdcp.ConnectionStateOnline))
+	require.True(t, restored.transition(id, sdcp.ConnectionStateOffline))
+	require.False(t, restored.transition(id, sdcp.ConnectionStateConnecting))
+	require.False(t, restored.transition(id, sdcp.ConnectionStateOffline))
+}